@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/LederWorks/gorepos/internal/executor"
+	"github.com/LederWorks/gorepos/internal/repository"
+	"github.com/LederWorks/gorepos/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// runExec executes the exec command
+func runExec(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Override workers from command line if provided
+	if cmd.Flags().Changed("parallel") {
+		cfg.Global.Workers = workers
+	}
+
+	command := execArgv(args, execShell)
+
+	repoManager := repository.NewManagerFromConfig(&cfg.Global)
+
+	// Filter repositories based on current working directory context
+	contextRepos := filterRepositoriesByContext(cfg.Repositories, cfg.Global.BasePath)
+
+	var operations []types.Operation
+	width := 0
+	for i := range contextRepos {
+		repo := &contextRepos[i]
+		if repo.Disabled {
+			continue
+		}
+		if !repoManager.Exists(repo) {
+			fmt.Printf("Repository %s does not exist at %s (run 'gorepos clone' first)\n", repo.Name, repo.Path)
+			continue
+		}
+
+		operations = append(operations, execOperation(repo, command))
+		width = max(width, len(repo.Name))
+	}
+
+	if len(operations) == 0 {
+		fmt.Println("No repositories to run in")
+		return nil
+	}
+
+	if dryRun {
+		fmt.Printf("DRY RUN MODE - Would run %q in:\n", strings.Join(command, " "))
+		for _, op := range operations {
+			fmt.Printf("  - %s (%s)\n", op.Repository.Name, op.Repository.Path)
+		}
+		return nil
+	}
+
+	out := &prefixedOutput{w: os.Stdout, width: width}
+	var onLine executor.LineFunc
+	if !execCollect {
+		onLine = func(op *types.Operation, line string) { out.print(op.Repository.Name, line) }
+	}
+	exec := newExecPool(repoManager, cfg.Global.Workers, onLine)
+
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	var summary executor.Summary
+	var results []types.Result
+	for result := range exec.Execute(ctx, operations) {
+		summary.Add(result)
+		results = append(results, result)
+		switch {
+		case execCollect:
+			printCollectedOutput(os.Stdout, result)
+		case result.Error != nil && result.ExitCode <= 0:
+			// The command did not run to completion, so it printed no reason
+			out.print(result.Repository.Name, fmt.Sprintf("Error: %v", result.Error))
+		}
+	}
+	interrupted := ctx.Err() != nil
+	if interrupted {
+		fmt.Println("Interrupted; running commands were cancelled")
+	}
+
+	fmt.Println(strings.Repeat("=", 40))
+	printExecTable(os.Stdout, results, width)
+	fmt.Println(summary.String())
+
+	if err := exec.Shutdown(context.Background()); err != nil {
+		return err
+	}
+	return exitForSummary(cmd, summary, interrupted)
+}
+
+// execArgv returns the argv to run for the exec arguments. With shell the
+// first argument is the script for sh -c and the rest become its positional
+// parameters, so they keep their quoting.
+func execArgv(args []string, shell bool) []string {
+	if !shell {
+		return args
+	}
+	return append([]string{"sh", "-c", args[0], "sh"}, args[1:]...)
+}
+
+// execOperation returns the exec operation running argv in the repository. A
+// repository's timeout bounds its git operations, so it is cleared here and
+// the command only gets the --timeout deadline.
+func execOperation(repo *types.Repository, argv []string) types.Operation {
+	execRepo := *repo
+	execRepo.Timeout = 0
+	return types.Operation{
+		Repository: &execRepo,
+		Command:    executor.OpExec,
+		Args:       argv,
+	}
+}
+
+// newExecPool creates a pool that runs exec operations with the manager,
+// passing each output line to onLine when it is set. Commands only time out
+// when --timeout is given.
+func newExecPool(manager types.RepositoryManager, workerCount int, onLine executor.LineFunc) *executor.Pool {
+	exec := executor.NewPool(workerCount)
+	exec.SetTimeout(execTimeout)
+	exec.RegisterHandler(executor.OpExec, executor.ExecHandler(manager, onLine))
+	return exec
+}
+
+// prefixedOutput prints output lines of concurrently running commands, each
+// prefixed with its repository name
+type prefixedOutput struct {
+	mu    sync.Mutex
+	w     io.Writer
+	width int // Width of the longest repository name, for alignment
+}
+
+// print writes one line as "<repository> | <line>"
+func (o *prefixedOutput) print(name, line string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	fmt.Fprintf(o.w, "%-*s | %s\n", o.width, name, line)
+}
+
+// printCollectedOutput prints a finished command's output under its repository
+func printCollectedOutput(w io.Writer, result types.Result) {
+	fmt.Fprintf(w, "\n%s:\n", result.Repository.Name)
+	output := strings.TrimRight(result.Output, "\n")
+	if output == "" && result.Error != nil && result.ExitCode <= 0 {
+		fmt.Fprintf(w, "  Error: %v\n", result.Error)
+		return
+	}
+	if output != "" {
+		for _, line := range strings.Split(output, "\n") {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+}
+
+// printExecTable prints each repository's exit code and duration, sorted by
+// repository name
+func printExecTable(w io.Writer, results []types.Result, width int) {
+	width = max(width, len("Repository"))
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Repository.Name < results[j].Repository.Name
+	})
+
+	fmt.Fprintf(w, "%-*s  %-8s  %s\n", width, "Repository", "Exit", "Duration")
+	for _, result := range results {
+		fmt.Fprintf(w, "%-*s  %-8s  %s\n", width, result.Repository.Name, describeExit(result), result.Duration.Round(time.Millisecond))
+	}
+}
+
+// describeExit names how a command ended: its exit code, or why it has none
+func describeExit(result types.Result) string {
+	switch {
+	case result.State == types.ResultTimedOut:
+		return "timeout"
+	case result.State == types.ResultCancelled:
+		return "cancelled"
+	case result.State == types.ResultSkipped:
+		return "skipped"
+	case result.ExitCode > 0:
+		return fmt.Sprintf("%d", result.ExitCode)
+	case result.Error != nil:
+		return "error"
+	default:
+		return "0"
+	}
+}
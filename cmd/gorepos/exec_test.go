@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LederWorks/gorepos/internal/repository"
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+func TestExecArgv(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		shell bool
+		want  []string
+	}{
+		{"command", []string{"git", "log", "-1"}, false, []string{"git", "log", "-1"}},
+		{"shell script", []string{"git log | wc -l"}, true, []string{"sh", "-c", "git log | wc -l", "sh"}},
+		{"shell parameters", []string{`echo "$1"`, "a b"}, true, []string{"sh", "-c", `echo "$1"`, "sh", "a b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := execArgv(tt.args, tt.shell); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeCheckout creates a directory the repository manager treats as a clone
+func fakeCheckout(t *testing.T, name string) *types.Repository {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), name)
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return &types.Repository{Name: name, Path: dir}
+}
+
+// runExecPool runs argv in each repository and returns the results and the
+// prefixed output
+func runExecPool(t *testing.T, argv []string, repos ...*types.Repository) ([]types.Result, string) {
+	t.Helper()
+	width := 0
+	var operations []types.Operation
+	for _, repo := range repos {
+		operations = append(operations, execOperation(repo, argv))
+		width = max(width, len(repo.Name))
+	}
+
+	var buf bytes.Buffer
+	out := &prefixedOutput{w: &buf, width: width}
+	pool := newExecPool(repository.NewManager(""), 2, func(op *types.Operation, line string) {
+		out.print(op.Repository.Name, line)
+	})
+	defer pool.Shutdown(context.Background())
+
+	var results []types.Result
+	for result := range pool.Execute(context.Background(), operations) {
+		results = append(results, result)
+	}
+	return results, buf.String()
+}
+
+func TestExec_ShellKeepsQuotedArguments(t *testing.T) {
+	argv := execArgv([]string{`printf '<%s>\n' "$@"`, "two words", `it's "quoted"`}, true)
+	results, output := runExecPool(t, argv, fakeCheckout(t, "repo"))
+
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("expected the script to succeed, got %+v", results)
+	}
+	want := "repo | <two words>\nrepo | <it's \"quoted\">\n"
+	if output != want {
+		t.Errorf("got output %q, want %q", output, want)
+	}
+}
+
+func TestExec_PrefixesEachRepositoryLines(t *testing.T) {
+	argv := execArgv([]string{`echo one; echo two`}, true)
+	results, output := runExecPool(t, argv, fakeCheckout(t, "a"), fakeCheckout(t, "longer"))
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	sort.Strings(lines)
+	want := []string{"a      | one", "a      | two", "longer | one", "longer | two"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("got lines %q, want %q", lines, want)
+	}
+}
+
+func TestExec_TimeoutOnlyFromFlag(t *testing.T) {
+	repo := fakeCheckout(t, "repo")
+	repo.Timeout = time.Millisecond
+
+	results, _ := runExecPool(t, []string{"sleep", "0.2"}, repo)
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("expected no timeout by default, got %+v", results)
+	}
+
+	execTimeout = 50 * time.Millisecond
+	defer func() { execTimeout = 0 }()
+	results, _ = runExecPool(t, []string{"sleep", "5"}, repo)
+	if len(results) != 1 || results[0].State != types.ResultTimedOut {
+		t.Errorf("expected the --timeout deadline to apply, got %+v", results)
+	}
+}
+
+func TestPrintExecTable(t *testing.T) {
+	results := []types.Result{
+		{Repository: &types.Repository{Name: "failed"}, ExitCode: 2, Error: errors.New("exit status 2"), Duration: 1500 * time.Millisecond},
+		{Repository: &types.Repository{Name: "api"}, Success: true, Duration: 20 * time.Millisecond},
+		{Repository: &types.Repository{Name: "slow"}, State: types.ResultTimedOut, ExitCode: -1, Error: errors.New("timed out")},
+		{Repository: &types.Repository{Name: "missing"}, ExitCode: -1, Error: errors.New("not found")},
+	}
+
+	var buf bytes.Buffer
+	printExecTable(&buf, results, len("missing"))
+	want := strings.Join([]string{
+		"Repository  Exit      Duration",
+		"api         0         20ms",
+		"failed      2         1.5s",
+		"missing     error     0s",
+		"slow        timeout   0s",
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("got table\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPrefixedOutput_LinesDoNotInterleave(t *testing.T) {
+	var buf bytes.Buffer
+	out := &prefixedOutput{w: &buf, width: 2}
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"a", "bb"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				out.print(name, "line")
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if line != "a  | line" && line != "bb | line" {
+			t.Fatalf("unexpected line %q", line)
+		}
+	}
+}
@@ -2,14 +2,20 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/LederWorks/gorepos/internal/commands"
 	"github.com/LederWorks/gorepos/internal/config"
+	"github.com/LederWorks/gorepos/internal/display"
 	"github.com/LederWorks/gorepos/internal/executor"
 	"github.com/LederWorks/gorepos/internal/repository"
+	"github.com/LederWorks/gorepos/internal/state"
 	"github.com/LederWorks/gorepos/pkg/graph"
 	"github.com/LederWorks/gorepos/pkg/types"
 	"github.com/spf13/cobra"
@@ -24,11 +30,31 @@ var (
 	verbose bool
 	dryRun  bool
 
+	// update and clone failure policy flags
+	failFast    bool
+	maxFailures int
+	resume      bool
+
+	// update command flags
+	updateForce     bool
+	updateAutostash bool
+
 	// setup command flags
 	setupPath     string
 	setupBasePath string
 	setupIncludes []string
 	setupForce    bool
+
+	// prune-worktrees command flags
+	pruneYes bool
+
+	// sync-forks command flags
+	syncPush bool
+
+	// exec command flags
+	execShell   bool
+	execCollect bool
+	execTimeout time.Duration
 )
 
 var rootCmd = &cobra.Command{
@@ -90,6 +116,43 @@ var graphCmd = &cobra.Command{
 	RunE:  runGraph,
 }
 
+var pruneWorktreesCmd = &cobra.Command{
+	Use:   "prune-worktrees",
+	Short: "Remove worktrees that are no longer configured",
+	Long:  "Find worktrees of configured repositories that are not declared under worktrees: and offer to remove them",
+	RunE:  runPruneWorktrees,
+}
+
+var syncForksCmd = &cobra.Command{
+	Use:   "sync-forks",
+	Short: "Fast-forward forks from their upstream remote",
+	Long:  "Fast-forward the tracked branch of every repository with an upstream remote to upstream's branch, optionally pushing it to origin",
+	RunE:  runSyncForks,
+}
+
+var execCmd = &cobra.Command{
+	Use:   "exec [flags] [--] <command> [args...]",
+	Short: "Run a command in every repository",
+	Long:  "Run a command in every enabled repository in parallel, prefixing each output line with the repository name, then print each repository's exit code and duration",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runExec,
+}
+
+var fixRemotesCmd = &cobra.Command{
+	Use:   "fix-remotes",
+	Short: "Point origin of existing clones at the configured URL",
+	Long:  "Rewrite origin of every clone whose origin names a different repository than its configured url; use --dry-run to preview",
+	RunE:  runFixRemotes,
+}
+
+var envCmd = &cobra.Command{
+	Use:   "env <repository>",
+	Short: "Show the environment of a repository",
+	Long:  "Print the environment git and commands run with in a repository, and the layer that set each variable",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runEnv,
+}
+
 var setupCmd = &cobra.Command{
 	Use:   "setup",
 	Short: "Initialize user configuration",
@@ -104,6 +167,25 @@ func init() {
 	setupCmd.Flags().StringSliceVar(&setupIncludes, "includes", nil, "Include files or URLs to add to configuration")
 	setupCmd.Flags().BoolVarP(&setupForce, "force", "f", false, "Overwrite existing configuration file")
 
+	// Failure policy flags for commands that run operations
+	for _, cmd := range []*cobra.Command{updateCmd, cloneCmd} {
+		cmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop starting new operations after the first failure")
+		cmd.Flags().IntVar(&maxFailures, "max-failures", 0, "Stop starting new operations after N failures (0 means no limit)")
+		cmd.Flags().BoolVar(&resume, "resume", false, "Only run operations that failed or never ran in the previous run")
+	}
+
+	pruneWorktreesCmd.Flags().BoolVarP(&pruneYes, "yes", "y", false, "Remove without asking for confirmation")
+	syncForksCmd.Flags().BoolVar(&syncPush, "push", false, "Push the synchronized branch to origin")
+
+	// Flags after the command belong to it, so "gorepos exec git log -1" needs no "--"
+	execCmd.Flags().SetInterspersed(false)
+	execCmd.Flags().BoolVar(&execShell, "shell", false, "Run the first argument as an sh -c script, for pipelines and redirection; further arguments become $1, $2, ...")
+	execCmd.Flags().BoolVar(&execCollect, "collect", false, "Print each repository's output together when its command finishes instead of prefixing lines as they arrive")
+	execCmd.Flags().DurationVar(&execTimeout, "timeout", 0, "Cancel a repository's command after this long (0 means no timeout)")
+
+	updateCmd.Flags().BoolVar(&updateForce, "force", false, "Reset repositories even when unpushed commits would be lost or HEAD is on another branch")
+	updateCmd.Flags().BoolVar(&updateAutostash, "autostash", false, "Stash uncommitted changes before updating and re-apply them afterwards")
+
 	// Add global flags
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "Configuration file path")
 	rootCmd.PersistentFlags().IntVarP(&workers, "parallel", "p", 10, "Number of parallel workers")
@@ -119,10 +201,19 @@ func init() {
 	rootCmd.AddCommand(groupsCmd)
 	rootCmd.AddCommand(graphCmd)
 	rootCmd.AddCommand(setupCmd)
+	rootCmd.AddCommand(pruneWorktreesCmd)
+	rootCmd.AddCommand(syncForksCmd)
+	rootCmd.AddCommand(fixRemotesCmd)
+	rootCmd.AddCommand(execCmd)
+	rootCmd.AddCommand(envCmd)
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -228,8 +319,12 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	}
 
 	ctx := context.Background()
-	repoManager := repository.NewManager(cfg.Global.BasePath)
-	exec := executor.NewPool(cfg.Global.Workers)
+	repoManager := repository.NewManagerFromConfig(&cfg.Global)
+	repoManager.SetUpdateOptions(repository.UpdateOptions{Force: updateForce, Autostash: updateAutostash})
+	exec := executor.NewManagedPool(cfg.Global.Workers, repoManager)
+	exec.SetTimeout(cfg.Global.Timeout)
+	exec.SetRetryPolicy(executor.NewRetryPolicy(cfg.Global.Retry))
+	exec.SetHostLimits(executor.NewHostLimits(cfg.Global.HostLimits))
 
 	fmt.Printf("GoRepos Update (workers: %d)\n", cfg.Global.Workers)
 	fmt.Println(strings.Repeat("=", 40))
@@ -239,7 +334,6 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 
 	// Prepare operations for enabled repositories that exist in current context
 	var operations []types.Operation
-	updatedRepos := make([]*types.Repository, 0)
 
 	for i := range contextRepos {
 		repo := &contextRepos[i]
@@ -255,14 +349,18 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
-		updatedRepos = append(updatedRepos, repo)
 		operations = append(operations, types.Operation{
 			Repository: repo,
-			Command:    "update",
+			Command:    executor.OpUpdate,
 			Context:    ctx,
 		})
 	}
 
+	journal, operations, err := prepareJournal(cfg.Global.BasePath, executor.OpUpdate, operations)
+	if err != nil {
+		return err
+	}
+
 	if len(operations) == 0 {
 		fmt.Println("No repositories to update")
 		return nil
@@ -270,24 +368,24 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 
 	if dryRun {
 		fmt.Println("DRY RUN MODE - Would update:")
-		for _, repo := range updatedRepos {
-			fmt.Printf("  - %s (%s)\n", repo.Name, repo.Path)
+		for _, op := range operations {
+			fmt.Printf("  - %s (%s)\n", op.Repository.Name, op.Repository.Path)
 		}
 		return nil
 	}
 
-	// Execute update operations
-	for _, repo := range updatedRepos {
-		fmt.Printf("Updating %s...", repo.Name)
-		err := repoManager.Update(ctx, repo)
-		if err != nil {
-			fmt.Printf(" ERROR: %v\n", err)
-		} else {
-			fmt.Printf(" OK\n")
-		}
-	}
+	// Execute update operations in parallel
+	exec.SetJournal(journal)
+	summary, interrupted := runOperations(ctx, exec, operations, "Updating")
+
+	fmt.Println(strings.Repeat("=", 40))
+	fmt.Println(summary.String())
+	reportJournal(journal, interrupted)
 
-	return exec.Shutdown(ctx)
+	if err := exec.Shutdown(ctx); err != nil {
+		return err
+	}
+	return exitForSummary(cmd, summary, interrupted)
 }
 
 // runClone executes the clone command
@@ -303,8 +401,11 @@ func runClone(cmd *cobra.Command, args []string) error {
 	}
 
 	ctx := context.Background()
-	repoManager := repository.NewManager(cfg.Global.BasePath)
-	exec := executor.NewPool(cfg.Global.Workers)
+	repoManager := repository.NewManagerFromConfig(&cfg.Global)
+	exec := executor.NewManagedPool(cfg.Global.Workers, repoManager)
+	exec.SetTimeout(cfg.Global.Timeout)
+	exec.SetRetryPolicy(executor.NewRetryPolicy(cfg.Global.Retry))
+	exec.SetHostLimits(executor.NewHostLimits(cfg.Global.HostLimits))
 
 	fmt.Printf("GoRepos Clone (workers: %d)\n", cfg.Global.Workers)
 	fmt.Println(strings.Repeat("=", 40))
@@ -314,7 +415,6 @@ func runClone(cmd *cobra.Command, args []string) error {
 
 	// Prepare operations for enabled repositories that don't exist in current context
 	var operations []types.Operation
-	clonedRepos := make([]*types.Repository, 0)
 
 	for i := range contextRepos {
 		repo := &contextRepos[i]
@@ -329,17 +429,22 @@ func runClone(cmd *cobra.Command, args []string) error {
 			if verbose {
 				fmt.Printf("Repository %s already exists at %s\n", repo.Name, repo.Path)
 			}
+			ensureWorktrees(ctx, repoManager, repo)
 			continue
 		}
 
-		clonedRepos = append(clonedRepos, repo)
 		operations = append(operations, types.Operation{
 			Repository: repo,
-			Command:    "clone",
+			Command:    executor.OpClone,
 			Context:    ctx,
 		})
 	}
 
+	journal, operations, err := prepareJournal(cfg.Global.BasePath, executor.OpClone, operations)
+	if err != nil {
+		return err
+	}
+
 	if len(operations) == 0 {
 		fmt.Println("No repositories to clone")
 		return nil
@@ -347,24 +452,282 @@ func runClone(cmd *cobra.Command, args []string) error {
 
 	if dryRun {
 		fmt.Println("DRY RUN MODE - Would clone:")
-		for _, repo := range clonedRepos {
-			fmt.Printf("  - %s -> %s\n", repo.URL, repo.Path)
+		for _, op := range operations {
+			fmt.Printf("  - %s -> %s\n", op.Repository.URL, op.Repository.Path)
 		}
 		return nil
 	}
 
-	// Execute clone operations
-	for _, repo := range clonedRepos {
-		fmt.Printf("Cloning %s...", repo.Name)
-		err := repoManager.Clone(ctx, repo)
+	// Execute clone operations in parallel
+	exec.SetJournal(journal)
+	summary, interrupted := runOperations(ctx, exec, operations, "Cloning")
+
+	fmt.Println(strings.Repeat("=", 40))
+	fmt.Println(summary.String())
+	reportJournal(journal, interrupted)
+
+	if err := exec.Shutdown(ctx); err != nil {
+		return err
+	}
+	return exitForSummary(cmd, summary, interrupted)
+}
+
+// opSyncFork is the pool operation registered by the sync-forks command
+const opSyncFork = "sync-fork"
+
+// runSyncForks executes the sync-forks command
+func runSyncForks(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Override workers from command line if provided
+	if cmd.Flags().Changed("parallel") {
+		cfg.Global.Workers = workers
+	}
+
+	ctx := context.Background()
+	repoManager := repository.NewManagerFromConfig(&cfg.Global)
+	exec := executor.NewManagedPool(cfg.Global.Workers, repoManager)
+	exec.SetTimeout(cfg.Global.Timeout)
+	exec.SetRetryPolicy(executor.NewRetryPolicy(cfg.Global.Retry))
+	exec.SetHostLimits(executor.NewHostLimits(cfg.Global.HostLimits))
+	exec.OrderByDependencies(opSyncFork)
+	exec.RegisterNetworkHandler(opSyncFork, func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		report, err := repoManager.SyncFork(ctx, op.Repository, syncPush)
 		if err != nil {
-			fmt.Printf(" ERROR: %v\n", err)
-		} else {
-			fmt.Printf(" OK\n")
+			return err
 		}
+		result.Update = report
+		result.Output = fmt.Sprintf("Synchronized fork at %s (%s)", op.Repository.Path, report)
+		return nil
+	})
+
+	fmt.Printf("GoRepos Sync Forks (workers: %d)\n", cfg.Global.Workers)
+	fmt.Println(strings.Repeat("=", 40))
+
+	// Filter repositories based on current working directory context
+	contextRepos := filterRepositoriesByContext(cfg.Repositories, cfg.Global.BasePath)
+
+	// Prepare operations for enabled forks that exist in current context
+	var operations []types.Operation
+
+	for i := range contextRepos {
+		repo := &contextRepos[i]
+		if repo.Disabled || repo.Remotes[types.UpstreamRemote] == "" {
+			continue
+		}
+
+		if !repoManager.Exists(repo) {
+			fmt.Printf("Repository %s does not exist at %s (run 'gorepos clone' first)\n", repo.Name, repo.Path)
+			continue
+		}
+
+		operations = append(operations, types.Operation{
+			Repository: repo,
+			Command:    opSyncFork,
+			Context:    ctx,
+		})
+	}
+
+	if len(operations) == 0 {
+		fmt.Printf("No repositories with an %s remote\n", types.UpstreamRemote)
+		return nil
+	}
+
+	if dryRun {
+		fmt.Println("DRY RUN MODE - Would sync:")
+		for _, op := range operations {
+			fmt.Printf("  - %s from %s\n", op.Repository.Name, op.Repository.Remotes[types.UpstreamRemote])
+		}
+		return nil
+	}
+
+	summary, interrupted := runOperations(ctx, exec, operations, "Syncing")
+
+	fmt.Println(strings.Repeat("=", 40))
+	fmt.Println(summary.String())
+
+	if err := exec.Shutdown(ctx); err != nil {
+		return err
+	}
+	return exitForSummary(cmd, summary, interrupted)
+}
+
+// runOperations executes operations on the pool while rendering live progress,
+// and returns the tally of their results. Ctrl-C stops the run cleanly and is
+// reported as interrupted; a second Ctrl-C exits immediately.
+func runOperations(ctx context.Context, exec *executor.Pool, operations []types.Operation, verb string) (executor.Summary, bool) {
+	events, unsubscribe := exec.Subscribe()
+	progress := display.NewProgressDisplay(os.Stdout, display.IsTerminal(os.Stdout), func(result types.Result) string {
+		return formatResult(verb, result)
+	})
+	progress.Start(events)
+
+	stopResize := watchResizeSignals(exec)
+	defer stopResize()
+
+	ctx, stopSignals := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	go func() {
+		<-ctx.Done()
+		stopSignals()
+	}()
+
+	limit := failureLimit(failFast, maxFailures)
+	exec.SetFailureLimit(limit)
+
+	var summary executor.Summary
+	for result := range exec.Execute(ctx, operations) {
+		summary.Add(result)
+	}
+
+	unsubscribe()
+	progress.Wait()
+
+	interrupted := ctx.Err() != nil
+	switch {
+	case interrupted:
+		fmt.Println("Interrupted; running operations were cancelled")
+	case summary.ReachedFailureLimit(limit):
+		fmt.Printf("Stopped after %d failure(s); remaining operations were cancelled\n", summary.Failures())
+	}
+
+	return summary, interrupted
+}
+
+// prepareJournal creates the journal for this run. With --resume it loads the
+// previous run's journal and narrows operations to those left unfinished.
+func prepareJournal(basePath, command string, operations []types.Operation) (*executor.Journal, []types.Operation, error) {
+	path := state.Path(basePath, fmt.Sprintf("journal-%s.json", command))
+	if !resume {
+		return executor.NewJournal(path, command), operations, nil
+	}
+
+	journal, err := executor.LoadJournal(path)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Println("No journal from a previous run; running all operations")
+		return executor.NewJournal(path, command), operations, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pending := journal.Pending(operations)
+	fmt.Printf("Resuming: %d of %d operations left from the previous run\n", len(pending), len(operations))
+	return journal, pending, nil
+}
+
+// reportJournal tells the user where progress was saved and how to resume
+func reportJournal(journal *executor.Journal, interrupted bool) {
+	if err := journal.Err(); err != nil {
+		fmt.Printf("Warning: could not save run journal: %v\n", err)
+		return
+	}
+	if interrupted {
+		fmt.Printf("Progress saved to %s; run again with --resume to continue\n", journal.Path())
+	}
+}
+
+// Process exit codes for commands that run operations
+const (
+	exitPartialFailure = 2 // some operations succeeded and some did not
+	exitTotalFailure   = 3 // no operation succeeded
+	exitInterrupted    = 130
+)
+
+// exitCodeError carries a process exit code out of a command without
+// printing an additional error message
+type exitCodeError struct {
+	code int
+	msg  string
+}
+
+func (e *exitCodeError) Error() string {
+	return e.msg
+}
+
+// failureLimit returns the number of failures after which a run stops
+// starting operations, 0 for no limit
+func failureLimit(failFast bool, maxFailures int) int {
+	if failFast {
+		return 1
+	}
+	return max(maxFailures, 0)
+}
+
+// exitCode maps the outcome of a run to the process exit code
+func exitCode(summary executor.Summary, interrupted bool) int {
+	switch {
+	case interrupted:
+		return exitInterrupted
+	case summary.Unsuccessful() == 0:
+		return 0
+	case summary.Succeeded == 0:
+		return exitTotalFailure
+	default:
+		return exitPartialFailure
 	}
+}
 
-	return exec.Shutdown(ctx)
+// exitForSummary returns an exitCodeError when the run was interrupted or any
+// operation did not succeed
+func exitForSummary(cmd *cobra.Command, summary executor.Summary, interrupted bool) error {
+	code := exitCode(summary, interrupted)
+	if code == 0 {
+		return nil
+	}
+
+	// The summary has already been printed
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	switch code {
+	case exitInterrupted:
+		return &exitCodeError{code: code, msg: "interrupted"}
+	case exitTotalFailure:
+		return &exitCodeError{code: code, msg: "all operations failed"}
+	default:
+		return &exitCodeError{code: code, msg: fmt.Sprintf("%d of %d operations did not succeed", summary.Unsuccessful(), summary.Total)}
+	}
+}
+
+// formatResult renders a finished operation as "<verb> <repo>... <outcome>"
+func formatResult(verb string, result types.Result) string {
+	switch {
+	case result.State == types.ResultTimedOut:
+		return fmt.Sprintf("%s %s... TIMEOUT: %v", verb, result.Repository.Name, result.Error)
+	case result.State == types.ResultSkipped:
+		return fmt.Sprintf("%s %s... SKIPPED: %v", verb, result.Repository.Name, result.Error)
+	case result.State == types.ResultRefused:
+		return fmt.Sprintf("%s %s... REFUSED [%s]: %v", verb, result.Repository.Name, result.Category, result.Error)
+	case result.Error != nil:
+		return fmt.Sprintf("%s %s... ERROR [%s]: %v", verb, result.Repository.Name, result.Category, result.Error)
+	case result.Update != nil:
+		return fmt.Sprintf("%s %s... OK (%s, %s)", verb, result.Repository.Name, result.Update, result.Duration.Round(time.Millisecond))
+	default:
+		return fmt.Sprintf("%s %s... OK (%s)", verb, result.Repository.Name, result.Duration.Round(time.Millisecond))
+	}
+}
+
+// ensureWorktrees adds configured worktrees missing from an existing clone.
+// New clones get their worktrees from the clone operation itself.
+func ensureWorktrees(ctx context.Context, repoManager *repository.Manager, repo *types.Repository) {
+	if dryRun {
+		for _, wt := range repoManager.MissingWorktrees(repo) {
+			fmt.Printf("Would add worktree %s (%s) to %s\n", wt.Path, wt.Branch, repo.Name)
+		}
+		return
+	}
+
+	created, err := repoManager.EnsureWorktrees(ctx, repo)
+	for _, path := range created {
+		fmt.Printf("Added worktree %s to %s\n", path, repo.Name)
+	}
+	if err != nil {
+		fmt.Printf("Could not add worktrees to %s: %v\n", repo.Name, err)
+	}
 }
 
 // runValidate executes the validate command
@@ -376,7 +739,7 @@ func runValidate(cmd *cobra.Command, args []string) error {
 // runRepos executes the repos command
 func runRepos(cmd *cobra.Command, args []string) error {
 	reposCmd := commands.NewReposCommand()
-	return reposCmd.Execute(cfgFile, verbose)
+	return reposCmd.Execute(cfgFile, verbose, workers)
 }
 
 // runGroups executes the groups command
@@ -385,6 +748,24 @@ func runGroups(cmd *cobra.Command, args []string) error {
 	return groupsCmd.Execute(cfgFile, verbose)
 }
 
+// runPruneWorktrees executes the prune-worktrees command
+func runPruneWorktrees(cmd *cobra.Command, args []string) error {
+	pruneCmd := commands.NewPruneWorktreesCommand()
+	return pruneCmd.Execute(cfgFile, verbose, workers, dryRun, pruneYes)
+}
+
+// runFixRemotes executes the fix-remotes command
+func runFixRemotes(cmd *cobra.Command, args []string) error {
+	fixCmd := commands.NewFixRemotesCommand()
+	return fixCmd.Execute(cfgFile, verbose, workers, dryRun)
+}
+
+// runEnv executes the env command
+func runEnv(cmd *cobra.Command, args []string) error {
+	envCmd := commands.NewEnvCommand()
+	return envCmd.Execute(cfgFile, verbose, args[0])
+}
+
 // runGraph executes the graph command
 func runGraph(cmd *cobra.Command, args []string) error {
 	graphCmd := commands.NewGraphCommand()
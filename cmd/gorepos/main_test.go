@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/LederWorks/gorepos/internal/executor"
+	"github.com/LederWorks/gorepos/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func TestFailureLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		failFast    bool
+		maxFailures int
+		want        int
+	}{
+		{"no limit", false, 0, 0},
+		{"max failures", false, 3, 3},
+		{"negative max failures", false, -1, 0},
+		{"fail fast", true, 0, 1},
+		{"fail fast wins over max failures", true, 5, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failureLimit(tt.failFast, tt.maxFailures); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// summaryOf tallies results in the given states
+func summaryOf(states ...types.ResultState) executor.Summary {
+	var s executor.Summary
+	for _, state := range states {
+		s.Add(types.Result{State: state, Success: state == types.ResultSucceeded})
+	}
+	return s
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		summary     executor.Summary
+		interrupted bool
+		want        int
+	}{
+		{"all succeeded", summaryOf(types.ResultSucceeded, types.ResultSucceeded), false, 0},
+		{"nothing ran", summaryOf(), false, 0},
+		{"partial failure", summaryOf(types.ResultSucceeded, types.ResultFailed), false, exitPartialFailure},
+		{"partial with skipped", summaryOf(types.ResultSucceeded, types.ResultSkipped), false, exitPartialFailure},
+		{"total failure", summaryOf(types.ResultFailed, types.ResultTimedOut), false, exitTotalFailure},
+		{"stopped at limit", summaryOf(types.ResultFailed, types.ResultCancelled, types.ResultCancelled), false, exitTotalFailure},
+		{"interrupted", summaryOf(types.ResultSucceeded, types.ResultCancelled), true, exitInterrupted},
+		{"interrupted after success", summaryOf(types.ResultSucceeded), true, exitInterrupted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.summary, tt.interrupted); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitForSummary(t *testing.T) {
+	cmd := &cobra.Command{}
+	if err := exitForSummary(cmd, summaryOf(types.ResultSucceeded), false); err != nil {
+		t.Fatalf("expected no error for a successful run, got %v", err)
+	}
+	if cmd.SilenceErrors {
+		t.Error("expected errors not to be silenced for a successful run")
+	}
+
+	err := exitForSummary(cmd, summaryOf(types.ResultSucceeded, types.ResultFailed, types.ResultFailed), false)
+	var exitErr *exitCodeError
+	if !errors.As(err, &exitErr) || exitErr.code != exitPartialFailure {
+		t.Fatalf("expected exit code %d, got %v", exitPartialFailure, err)
+	}
+	if exitErr.Error() != "2 of 3 operations did not succeed" {
+		t.Errorf("unexpected message %q", exitErr.Error())
+	}
+	if !cmd.SilenceErrors || !cmd.SilenceUsage {
+		t.Error("expected the already printed summary not to be followed by an error")
+	}
+}
@@ -0,0 +1,40 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/LederWorks/gorepos/internal/executor"
+)
+
+// watchResizeSignals lets a running batch be throttled without cancelling it:
+// SIGUSR1 adds a worker and SIGUSR2 removes one. The returned function stops
+// watching.
+func watchResizeSignals(exec *executor.Pool) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if sig == syscall.SIGUSR1 {
+					exec.SetWorkerCount(exec.GetWorkerCount() + 1)
+				} else {
+					exec.SetWorkerCount(exec.GetWorkerCount() - 1)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
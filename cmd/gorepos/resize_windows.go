@@ -0,0 +1,10 @@
+//go:build windows
+
+package main
+
+import "github.com/LederWorks/gorepos/internal/executor"
+
+// watchResizeSignals is a no-op on Windows, which has no user signals
+func watchResizeSignals(exec *executor.Pool) func() {
+	return func() {}
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LederWorks/gorepos/internal/config"
+	"github.com/LederWorks/gorepos/internal/repository"
+)
+
+// EnvCommand prints the effective environment of a repository
+type EnvCommand struct{}
+
+// NewEnvCommand creates a new env command handler
+func NewEnvCommand() *EnvCommand {
+	return &EnvCommand{}
+}
+
+// Execute prints the environment git and commands run with in the named
+// repository, with the layer that set each variable. Variables inherited
+// unchanged from the process are only listed when verbose.
+func (c *EnvCommand) Execute(configFile string, verbose bool, repoName string) error {
+	configPath := configFile
+	if configPath == "" {
+		var err error
+		configPath, err = config.GetConfigPath()
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := config.NewLoader().LoadConfigWithDetails(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	cfg := result.Config
+
+	var names []string
+	for i := range cfg.Repositories {
+		repo := &cfg.Repositories[i]
+		if repo.Name != repoName {
+			names = append(names, repo.Name)
+			continue
+		}
+
+		fmt.Printf("GoRepos Environment: %s\n", repo.Name)
+		fmt.Println(strings.Repeat("=", 40))
+
+		inherited := 0
+		for _, v := range repository.NewManagerFromConfig(&cfg.Global).Environment(repo) {
+			if v.Layer == repository.EnvProcess && !verbose {
+				inherited++
+				continue
+			}
+			fmt.Printf("%s=%s (%s)\n", v.Name, v.Value, v.Layer)
+		}
+		if inherited > 0 {
+			fmt.Printf("\n%d variable(s) inherited from the process environment (use --verbose to list them)\n", inherited)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("repository %q not found in configuration (known: %s)", repoName, strings.Join(names, ", "))
+}
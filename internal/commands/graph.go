@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,6 +9,8 @@ import (
 
 	"github.com/LederWorks/gorepos/internal/config"
 	"github.com/LederWorks/gorepos/internal/display"
+	"github.com/LederWorks/gorepos/internal/executor"
+	"github.com/LederWorks/gorepos/internal/repository"
 	"github.com/LederWorks/gorepos/pkg/types"
 )
 
@@ -54,8 +57,18 @@ func (c *GraphCommand) Execute(cfgFile string, verbose bool) error {
 	// Use the display package to show the configuration tree
 	display := display.NewConfigTreeDisplay()
 
+	// Working tree state is read once per repository and shared by every node listing it
+	states := c.collectStates(result.Config)
+
+	// Detected default branches are shown for repositories without a configured
+	// branch. Reading status detects and saves missing ones, so load them after.
+	detected, err := repository.LoadDetectedBranches(result.Config.Global.BasePath)
+	if err != nil && verbose {
+		fmt.Printf("Warning: could not read detected branches: %v\n", err)
+	}
+
 	// Convert config FileNode to display FileNode
-	displayNodes := c.convertToDisplayNodes(result.FileHierarchy)
+	displayNodes := c.convertToDisplayNodes(result.FileHierarchy, detected, states)
 
 	if len(contextRepoNames) > 0 {
 		// Show context-filtered tree
@@ -84,6 +97,52 @@ func (c *GraphCommand) Execute(cfgFile string, verbose bool) error {
 	return nil
 }
 
+// collectStates reads the status of every enabled repository in parallel and
+// summarizes it by repository name
+func (c *GraphCommand) collectStates(cfg *types.Config) map[string]string {
+	manager := repository.NewManagerFromConfig(&cfg.Global)
+	manager.EnableStatusCache()
+
+	pool := executor.NewManagedPool(cfg.Global.Workers, manager)
+	pool.SetTimeout(cfg.Global.Timeout)
+
+	ctx := context.Background()
+	var operations []types.Operation
+	for i := range cfg.Repositories {
+		if cfg.Repositories[i].Disabled {
+			continue
+		}
+		operations = append(operations, types.Operation{
+			Repository: &cfg.Repositories[i],
+			Command:    executor.OpStatus,
+			Context:    ctx,
+		})
+	}
+
+	states := make(map[string]string, len(operations))
+	for result := range pool.Execute(ctx, operations) {
+		states[result.Repository.Name] = describeState(result)
+	}
+	pool.Shutdown(ctx)
+	return states
+}
+
+// describeState summarizes a status result for the graph
+func describeState(result types.Result) string {
+	switch {
+	case types.CategoryOf(result.Error) == types.ErrorNotFound:
+		return "not cloned"
+	case result.Error != nil || result.Status == nil:
+		return "status unavailable"
+	case result.Status.Mirror != nil:
+		return "mirror"
+	case result.Status.IsClean:
+		return "clean"
+	default:
+		return strings.Join(describeChanges(result.Status), ", ")
+	}
+}
+
 // loadConfigWithVerbose loads configuration with verbose output if enabled
 func (c *GraphCommand) loadConfigWithVerbose(cfgFile string, verbose bool) (*config.ConfigLoadResult, error) {
 	loader := config.NewLoader()
@@ -156,14 +215,14 @@ func (c *GraphCommand) getContextRepositoryNames(repositories []types.Repository
 }
 
 // convertToDisplayNodes converts config FileNode to display FileNode
-func (c *GraphCommand) convertToDisplayNodes(nodes []config.FileNode) []display.FileNode {
+func (c *GraphCommand) convertToDisplayNodes(nodes []config.FileNode, detected map[string]repository.DetectedBranch, states map[string]string) []display.FileNode {
 	var result []display.FileNode
 	for _, node := range nodes {
 		displayNode := display.FileNode{
 			Path:         node.Path,
-			Repositories: c.convertRepositoryInfo(node.Repositories),
+			Repositories: c.convertRepositoryInfo(node.Repositories, detected, states),
 			IsValid:      node.IsValid,
-			Includes:     c.convertToDisplayNodes(node.Includes),
+			Includes:     c.convertToDisplayNodes(node.Includes, detected, states),
 		}
 		result = append(result, displayNode)
 	}
@@ -171,12 +230,19 @@ func (c *GraphCommand) convertToDisplayNodes(nodes []config.FileNode) []display.
 }
 
 // convertRepositoryInfo converts config RepositoryInfo to display RepositoryInfo
-func (c *GraphCommand) convertRepositoryInfo(repos []config.RepositoryInfo) []display.RepositoryInfo {
+func (c *GraphCommand) convertRepositoryInfo(repos []config.RepositoryInfo, detected map[string]repository.DetectedBranch, states map[string]string) []display.RepositoryInfo {
 	var result []display.RepositoryInfo
 	for _, repo := range repos {
 		displayRepo := display.RepositoryInfo{
-			Name:     repo.Name,
-			Disabled: repo.Disabled,
+			Name:         repo.Name,
+			Disabled:     repo.Disabled,
+			Branch:       repo.Branch,
+			BranchSource: string(types.BranchConfigured),
+			State:        states[repo.Name],
+		}
+		if repo.Branch == "" {
+			displayRepo.Branch = detected[repo.Name].Branch
+			displayRepo.BranchSource = string(types.BranchDetected)
 		}
 		result = append(result, displayRepo)
 	}
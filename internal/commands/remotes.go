@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/LederWorks/gorepos/internal/config"
+	"github.com/LederWorks/gorepos/internal/executor"
+	"github.com/LederWorks/gorepos/internal/repository"
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// opFixRemote is the pool operation registered by the fix-remotes command
+const opFixRemote = "fix-remote"
+
+// FixRemotesCommand points origin of existing clones back at their configured url
+type FixRemotesCommand struct{}
+
+// NewFixRemotesCommand creates a new fix-remotes command handler
+func NewFixRemotesCommand() *FixRemotesCommand {
+	return &FixRemotesCommand{}
+}
+
+// Execute finds clones whose origin names a different repository than the
+// configured url and rewrites origin. SSH and HTTPS forms of the same
+// repository are left alone. dryRun only lists the changes.
+func (c *FixRemotesCommand) Execute(configFile string, verbose bool, workers int, dryRun bool) error {
+	configPath := configFile
+	if configPath == "" {
+		var err error
+		configPath, err = config.GetConfigPath()
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := config.NewLoader().LoadConfigWithDetails(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	cfg := result.Config
+
+	// Override workers from command line if provided
+	if workers > 0 {
+		cfg.Global.Workers = workers
+	}
+
+	ctx := context.Background()
+	repoManager := repository.NewManagerFromConfig(&cfg.Global)
+	exec := executor.NewManagedPool(cfg.Global.Workers, repoManager)
+	exec.SetTimeout(cfg.Global.Timeout)
+	exec.SetRetryPolicy(executor.NewRetryPolicy(cfg.Global.Retry))
+	exec.RegisterHandler(opFixRemote, fixRemoteHandler(repoManager, dryRun))
+
+	fmt.Printf("GoRepos Fix Remotes (workers: %d)\n", cfg.Global.Workers)
+	fmt.Println(strings.Repeat("=", 40))
+
+	var operations []types.Operation
+	for i := range cfg.Repositories {
+		repo := &cfg.Repositories[i]
+		if repo.Disabled || !repoManager.Exists(repo) {
+			continue
+		}
+		operations = append(operations, types.Operation{
+			Repository: repo,
+			Command:    opFixRemote,
+			Context:    ctx,
+		})
+	}
+
+	if len(operations) == 0 {
+		fmt.Println("No cloned repositories to check")
+		return nil
+	}
+
+	var summary executor.Summary
+	fixed, found := 0, 0
+	for result := range exec.Execute(ctx, operations) {
+		summary.Add(result)
+		repo := result.Repository
+
+		switch {
+		case result.Error != nil:
+			found++
+			fmt.Printf("Could not update origin of %s: %v\n", repo.Name, result.Error)
+		case result.Output == "":
+			if verbose {
+				fmt.Printf("%s: origin matches %s\n", repo.Name, repo.URL)
+			}
+		default:
+			found++
+			if !dryRun {
+				fixed++
+			}
+			fmt.Println(result.Output)
+		}
+	}
+
+	if found == 0 {
+		fmt.Println("All origins match the configuration")
+	} else if !dryRun {
+		fmt.Printf("Updated %d of %d origin(s)\n", fixed, found)
+	}
+
+	fmt.Println(strings.Repeat("=", 40))
+	fmt.Println(summary.String())
+
+	return exec.Shutdown(ctx)
+}
+
+// fixRemoteHandler points origin of the operation's repository at its
+// configured url when it has drifted, describing the change in the result's
+// output. Matching origins leave the output empty; dryRun only describes.
+func fixRemoteHandler(repoManager *repository.Manager, dryRun bool) executor.Handler {
+	return func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		repo := op.Repository
+		current, drifted := repoManager.OriginDrift(ctx, repo)
+		if !drifted {
+			return nil
+		}
+
+		if dryRun {
+			result.Output = fmt.Sprintf("Would point origin of %s from %s to %s", repo.Name, describeOrigin(current), repo.URL)
+			return nil
+		}
+		if err := repoManager.FixOrigin(ctx, repo); err != nil {
+			return err
+		}
+		result.Output = fmt.Sprintf("Pointed origin of %s from %s to %s", repo.Name, describeOrigin(current), repo.URL)
+		return nil
+	}
+}
@@ -1,17 +1,23 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/LederWorks/gorepos/internal/config"
+	"github.com/LederWorks/gorepos/internal/executor"
+	"github.com/LederWorks/gorepos/internal/repository"
 	"github.com/LederWorks/gorepos/pkg/types"
 )
 
+// opGitInfo is the executor operation that collects GitInfo for the repos display
+const opGitInfo = "git-info"
+
 // GitInfo contains git repository status information
 type GitInfo struct {
 	Branch      string
@@ -19,13 +25,20 @@ type GitInfo struct {
 	HasStaged   bool
 	IsClean     bool
 	Exists      bool
+	Worktrees   []types.WorktreeStatus
+	Mirror      *types.MirrorStatus // Set for mirror clones instead of working tree state
+	Status      *types.RepoStatus   // Nil when the directory is not a readable repository
 }
 
 // ReposCommand handles the repository filesystem display command
 type ReposCommand struct {
 	configFile string
 	verbose    bool
+	workers    int
 	basePath   string
+	manager    *repository.Manager
+	gitInfo    map[string]GitInfo
+	gitInfoMu  sync.Mutex
 }
 
 // NewReposCommand creates a new repos command handler
@@ -34,9 +47,10 @@ func NewReposCommand() *ReposCommand {
 }
 
 // Execute runs the repos command
-func (r *ReposCommand) Execute(configFile string, verbose bool) error {
+func (r *ReposCommand) Execute(configFile string, verbose bool, workers int) error {
 	r.configFile = configFile
 	r.verbose = verbose
+	r.workers = workers
 
 	loader := config.NewLoader()
 
@@ -58,6 +72,14 @@ func (r *ReposCommand) Execute(configFile string, verbose bool) error {
 
 	// Store base path for git operations
 	r.basePath = result.Config.Global.BasePath
+	r.manager = repository.NewManagerFromConfig(&result.Config.Global)
+	r.manager.SetStatusOptions(repository.StatusOptions{CommitDetails: verbose})
+	r.manager.EnableStatusCache()
+
+	// Override workers from command line if provided
+	if workers > 0 {
+		result.Config.Global.Workers = workers
+	}
 
 	// Get current working directory for context
 	cwd, err := os.Getwd()
@@ -72,6 +94,7 @@ func (r *ReposCommand) Execute(configFile string, verbose bool) error {
 	// Apply context-aware filtering
 	contextRepos := r.filterRepositoriesByContext(result.Config.Repositories, result.Config.Global.BasePath)
 	if len(contextRepos) > 0 {
+		r.collectGitInfo(contextRepos, result.Config.Global.Workers)
 		r.printRepositoryTreeSimple(contextRepos, result.Config.Global.BasePath, cwd)
 	} else {
 		fmt.Println("No repositories in current context")
@@ -254,8 +277,8 @@ func (r *ReposCommand) printRepositoryWithGitInfo(repo types.Repository, prefix
 	// Repository type indicator (assume git for now)
 	typeIcon := "🔗" // Git icon
 
-	// Get git information
-	gitInfo := r.getGitInfo(repo)
+	// Get git information collected by the executor
+	gitInfo := r.lookupGitInfo(repo)
 
 	// Extract repository directory name from full name
 	repoDir := r.getRepositoryDirName(repo.Name)
@@ -269,10 +292,31 @@ func (r *ReposCommand) printRepositoryWithGitInfo(repo types.Repository, prefix
 	}
 
 	// Add branch info if repository exists
-	if gitInfo.Exists && gitInfo.Branch != "" {
+	if gitInfo.Exists && gitInfo.Mirror != nil {
+		displayStr += fmt.Sprintf(" (mirror, %d refs)", gitInfo.Mirror.Refs)
+	} else if gitInfo.Exists && gitInfo.Branch != "" {
 		displayStr += fmt.Sprintf(" (%s)", gitInfo.Branch)
 	}
 
+	// Flag clones whose origin no longer matches the configured url
+	if status := gitInfo.Status; status != nil && status.OriginMismatch {
+		displayStr += " [origin differs]"
+	}
+
+	// Summarize local changes and stashes
+	if status := gitInfo.Status; status != nil && gitInfo.Mirror == nil {
+		details := describeChanges(status)
+		if status.StashCount > 0 {
+			details = append(details, fmt.Sprintf("%d stashed", status.StashCount))
+		}
+		if len(details) > 0 {
+			displayStr += " [" + strings.Join(details, ", ") + "]"
+		}
+		if r.verbose && status.LastCommit != nil {
+			displayStr += " " + describeCommit(status.LastCommit)
+		}
+	}
+
 	// Add git status icon only for enabled repositories
 	// Disabled repositories use ○ symbol which already indicates they're not active
 	if !repo.Disabled {
@@ -285,6 +329,26 @@ func (r *ReposCommand) printRepositoryWithGitInfo(repo types.Repository, prefix
 	fmt.Print(displayStr)
 
 	fmt.Println()
+
+	childPrefix := prefix + "│   "
+	if isLast {
+		childPrefix = prefix + "    "
+	}
+	for i, wt := range gitInfo.Worktrees {
+		wtConnector := "├── "
+		if i == len(gitInfo.Worktrees)-1 {
+			wtConnector = "└── "
+		}
+		fmt.Printf("%s%s🌿 %s (%s) %s\n", childPrefix, wtConnector, r.worktreeDisplayPath(wt.Path), wt.Branch, describeWorktreeState(wt))
+	}
+}
+
+// worktreeDisplayPath shows a worktree path relative to the base path when it lives under it
+func (r *ReposCommand) worktreeDisplayPath(path string) string {
+	if rel, err := filepath.Rel(r.getBasePath(), path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
 }
 
 // getRepositoryDirName returns the repository name for display
@@ -293,6 +357,47 @@ func (r *ReposCommand) getRepositoryDirName(repoName string) string {
 	return repoName
 }
 
+// collectGitInfo gathers GitInfo for all repositories in parallel through the executor
+func (r *ReposCommand) collectGitInfo(repos []types.Repository, workers int) {
+	r.gitInfo = make(map[string]GitInfo, len(repos))
+
+	pool := executor.NewPool(workers)
+	pool.RegisterHandler(opGitInfo, func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		info := r.getGitInfo(*op.Repository)
+
+		r.gitInfoMu.Lock()
+		r.gitInfo[op.Repository.Name] = info
+		r.gitInfoMu.Unlock()
+		return nil
+	})
+
+	ctx := context.Background()
+	var operations []types.Operation
+	for i := range repos {
+		operations = append(operations, types.Operation{
+			Repository: &repos[i],
+			Command:    opGitInfo,
+			Context:    ctx,
+		})
+	}
+
+	for range pool.Execute(ctx, operations) {
+	}
+	pool.Shutdown(ctx)
+}
+
+// lookupGitInfo returns collected GitInfo, falling back to a direct lookup
+func (r *ReposCommand) lookupGitInfo(repo types.Repository) GitInfo {
+	r.gitInfoMu.Lock()
+	info, ok := r.gitInfo[repo.Name]
+	r.gitInfoMu.Unlock()
+
+	if ok {
+		return info
+	}
+	return r.getGitInfo(repo)
+}
+
 // getGitInfo retrieves git repository information
 func (r *ReposCommand) getGitInfo(repo types.Repository) GitInfo {
 	info := GitInfo{}
@@ -309,70 +414,34 @@ func (r *ReposCommand) getGitInfo(repo types.Repository) GitInfo {
 	}
 	info.Exists = true
 
-	// Get current branch
-	info.Branch = r.getCurrentBranch(repoPath)
-
-	// Get git status
-	info.HasUnstaged, info.HasStaged, info.IsClean = r.getGitStatus(repoPath)
-
-	return info
-}
-
-// getCurrentBranch gets the current git branch
-func (r *ReposCommand) getCurrentBranch(repoPath string) string {
-	cmd := exec.Command("git", "branch", "--show-current")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
-	if err != nil {
-		return "" // Not a git repository or git not available
-	}
-
-	branch := strings.TrimSpace(string(output))
-	if branch == "" {
-		// Might be in detached HEAD state, try to get commit
-		cmd = exec.Command("git", "rev-parse", "--short", "HEAD")
-		cmd.Dir = repoPath
-		if output, err := cmd.Output(); err == nil {
-			return "detached@" + strings.TrimSpace(string(output))
-		}
+	manager := r.manager
+	if manager == nil {
+		manager = repository.NewManager(r.getBasePath())
 	}
-
-	return branch
-}
-
-// getGitStatus checks git status for unstaged and staged files
-func (r *ReposCommand) getGitStatus(repoPath string) (hasUnstaged, hasStaged, isClean bool) {
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
+	status, err := manager.Status(context.Background(), &repo)
 	if err != nil {
-		return false, false, false // Error getting status
+		return info // Not a git repository or git not available
 	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(lines) == 1 && lines[0] == "" {
-		return false, false, true // Clean repository
+	info.Status = status
+	info.Worktrees = status.Worktrees
+
+	// Mirror clones are bare and have no working tree state
+	if status.Mirror != nil {
+		info.Branch = status.CurrentBranch
+		info.Mirror = status.Mirror
+		info.IsClean = true
+		return info
 	}
 
-	for _, line := range lines {
-		if len(line) >= 2 {
-			indexStatus := line[0]
-			workTreeStatus := line[1]
-
-			// Check for staged files (index status)
-			if indexStatus != ' ' && indexStatus != '?' {
-				hasStaged = true
-			}
-
-			// Check for unstaged files (work tree status)
-			if workTreeStatus != ' ' {
-				hasUnstaged = true
-			}
-		}
+	info.Branch = status.CurrentBranch
+	if status.Detached && status.LastCommit != nil {
+		info.Branch = "detached@" + shortHash(status.LastCommit.Hash)
 	}
+	info.HasStaged = len(status.Staged) > 0
+	info.HasUnstaged = len(status.Unstaged) > 0 || len(status.Untracked) > 0 || len(status.Conflicted) > 0
+	info.IsClean = status.IsClean
 
-	isClean = !hasStaged && !hasUnstaged
-	return hasUnstaged, hasStaged, isClean
+	return info
 }
 
 // getGitStatusIcon returns appropriate icon based on git status
@@ -385,6 +454,10 @@ func (r *ReposCommand) getGitStatusIcon(info GitInfo) string {
 		return "✅" // Clean/committed
 	}
 
+	if info.Status != nil && len(info.Status.Conflicted) > 0 {
+		return "⚠️" // Unresolved merge conflicts
+	}
+
 	if info.HasUnstaged {
 		return "❌" // Has unstaged files
 	}
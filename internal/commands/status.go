@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/LederWorks/gorepos/internal/config"
 	"github.com/LederWorks/gorepos/internal/executor"
@@ -48,8 +49,12 @@ func (s *StatusCommand) Execute(configFile string, verbose bool, workers int, dr
 	}
 
 	ctx := context.Background()
-	repoManager := repository.NewManager(cfg.Global.BasePath)
-	exec := executor.NewPool(cfg.Global.Workers)
+	repoManager := repository.NewManagerFromConfig(&cfg.Global)
+	repoManager.SetStatusOptions(repository.StatusOptions{CommitDetails: verbose})
+	repoManager.EnableStatusCache()
+	exec := executor.NewManagedPool(cfg.Global.Workers, repoManager)
+	exec.SetTimeout(cfg.Global.Timeout)
+	exec.SetRetryPolicy(executor.NewRetryPolicy(cfg.Global.Retry))
 
 	fmt.Printf("GoRepos Status (workers: %d)\n", cfg.Global.Workers)
 	fmt.Println(strings.Repeat("=", 40))
@@ -69,7 +74,7 @@ func (s *StatusCommand) Execute(configFile string, verbose bool, workers int, dr
 		enabledRepos = append(enabledRepos, repo)
 		operations = append(operations, types.Operation{
 			Repository: repo,
-			Command:    "status",
+			Command:    executor.OpStatus,
 			Context:    ctx,
 		})
 	}
@@ -91,29 +96,105 @@ func (s *StatusCommand) Execute(configFile string, verbose bool, workers int, dr
 	results := exec.Execute(ctx, operations)
 
 	// Process results
+	var summary executor.Summary
 	for result := range results {
+		summary.Add(result)
 		fmt.Printf("\n%s:\n", result.Repository.Name)
 
-		// Get actual repository status using the repository manager
-		status, err := repoManager.Status(ctx, result.Repository)
-		if err != nil {
-			fmt.Printf("  Error: %v\n", err)
+		if result.State == types.ResultTimedOut {
+			fmt.Printf("  Timeout: %v\n", result.Error)
+			continue
+		}
+		if result.State == types.ResultSkipped {
+			fmt.Printf("  Skipped: %v\n", result.Error)
+			continue
+		}
+		if result.Error != nil {
+			fmt.Printf("  Error: %v\n", result.Error)
 			continue
 		}
+		status := result.Status
 
 		fmt.Printf("  Path: %s\n", status.Path)
-		fmt.Printf("  Branch: %s\n", status.CurrentBranch)
+		if status.OriginMismatch {
+			fmt.Printf("  Origin: %s does not match configured %s; run 'gorepos fix-remotes' to update it\n", describeOrigin(status.OriginURL), result.Repository.URL)
+		}
+		if status.Mirror != nil {
+			printMirrorStatus(status)
+			continue
+		}
+		if status.Detached {
+			fmt.Printf("  Branch: (detached HEAD)\n")
+		} else {
+			fmt.Printf("  Branch: %s\n", status.CurrentBranch)
+		}
+		if status.Branch != "" {
+			fmt.Printf("  Tracking: origin/%s (%s)\n", status.Branch, status.BranchSource)
+		}
+		if status.Upstream != "" && status.Upstream != "origin/"+status.Branch {
+			fmt.Printf("  Upstream: %s\n", status.Upstream)
+		}
+		if status.LastCommit != nil {
+			fmt.Printf("  Last commit: %s\n", describeCommit(status.LastCommit))
+		}
+
+		var cloneFlags []string
+		if status.Shallow {
+			cloneFlags = append(cloneFlags, "shallow")
+		}
+		if status.Sparse {
+			cloneFlags = append(cloneFlags, "sparse")
+		}
+		if len(cloneFlags) > 0 {
+			fmt.Printf("  Clone: %s\n", strings.Join(cloneFlags, ", "))
+		}
+
+		if len(status.Submodules) > 0 {
+			fmt.Printf("  Submodules: %d out of sync\n", len(status.Submodules))
+			if verbose {
+				for _, sub := range status.Submodules {
+					fmt.Printf("    - %s (%s)\n", sub.Path, sub.State)
+				}
+			}
+		}
 
 		if status.IsClean {
 			fmt.Printf("  Status: Clean\n")
 		} else {
-			fmt.Printf("  Status: %d uncommitted files\n", len(status.UncommittedFiles))
+			fmt.Printf("  Status: %s\n", strings.Join(describeChanges(status), ", "))
 			if verbose {
-				for _, file := range status.UncommittedFiles {
-					fmt.Printf("    - %s\n", file)
+				for _, group := range []struct {
+					label string
+					files []string
+				}{
+					{"conflicted", status.Conflicted},
+					{"staged", status.Staged},
+					{"unstaged", status.Unstaged},
+					{"untracked", status.Untracked},
+				} {
+					for _, file := range group.files {
+						fmt.Printf("    - %s (%s)\n", file, group.label)
+					}
 				}
 			}
 		}
+		if status.StashCount > 0 {
+			fmt.Printf("  Stashes: %d\n", status.StashCount)
+		}
+
+		if len(status.Worktrees) > 0 {
+			fmt.Printf("  Worktrees:\n")
+			unconfigured := 0
+			for _, wt := range status.Worktrees {
+				fmt.Printf("    - %s (%s): %s\n", wt.Path, wt.Branch, describeWorktreeState(wt))
+				if !wt.Configured {
+					unconfigured++
+				}
+			}
+			if unconfigured > 0 {
+				fmt.Printf("  %d worktree(s) not in configuration; run 'gorepos prune-worktrees' to remove them\n", unconfigured)
+			}
+		}
 
 		if status.AheadBehind != nil {
 			if status.AheadBehind.Ahead > 0 || status.AheadBehind.Behind > 0 {
@@ -122,11 +203,137 @@ func (s *StatusCommand) Execute(configFile string, verbose bool, workers int, dr
 				fmt.Printf("  Sync: Up to date\n")
 			}
 		}
+
+		if len(status.Remotes) > 0 {
+			fmt.Printf("  Remotes:\n")
+			for _, remote := range status.Remotes {
+				fmt.Printf("    - %s: %s\n", remote.Name, describeRemoteSync(remote, status.Branch))
+				if verbose {
+					fmt.Printf("      %s\n", remote.URL)
+				}
+			}
+		}
 	}
 
+	fmt.Println()
+	fmt.Println(strings.Repeat("=", 40))
+	fmt.Println(summary.String())
+
 	return exec.Shutdown(ctx)
 }
 
+// describeWorktreeState summarizes a worktree for status and repos output
+func describeWorktreeState(wt types.WorktreeStatus) string {
+	var state string
+	switch {
+	case !wt.Exists && wt.Configured:
+		state = "missing, run 'gorepos clone' to add it"
+	case !wt.Exists:
+		state = "missing"
+	case wt.CurrentBranch != wt.Branch:
+		state = fmt.Sprintf("on %s", wt.CurrentBranch)
+		if wt.CurrentBranch == "" {
+			state = "detached"
+		}
+	case wt.IsClean:
+		state = "clean"
+	default:
+		state = "uncommitted changes"
+	}
+	if !wt.Configured {
+		state += ", not in configuration"
+	}
+	return state
+}
+
+// printMirrorStatus prints the ref counts and fetch time that stand in for
+// working tree state on mirror clones
+func printMirrorStatus(status *types.RepoStatus) {
+	mirror := status.Mirror
+	fmt.Printf("  Mode: mirror (HEAD %s)\n", status.CurrentBranch)
+	fmt.Printf("  Refs: %d (%d branches, %d tags)\n", mirror.Refs, mirror.Branches, mirror.Tags)
+	if mirror.LastFetch.IsZero() {
+		fmt.Printf("  Last fetch: unknown\n")
+	} else {
+		fmt.Printf("  Last fetch: %s (%s)\n", mirror.LastFetch.Format(time.RFC3339), describeAge(mirror.LastFetch))
+	}
+}
+
+// describeChanges counts a working tree's changes by kind, e.g. "2 staged, 1 untracked"
+func describeChanges(status *types.RepoStatus) []string {
+	var parts []string
+	for _, group := range []struct {
+		label string
+		count int
+	}{
+		{"conflicted", len(status.Conflicted)},
+		{"staged", len(status.Staged)},
+		{"unstaged", len(status.Unstaged)},
+		{"untracked", len(status.Untracked)},
+	} {
+		if group.count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", group.count, group.label))
+		}
+	}
+	return parts
+}
+
+// describeOrigin names origin's URL, or its absence
+func describeOrigin(url string) string {
+	if url == "" {
+		return "(none)"
+	}
+	return url
+}
+
+// describeCommit formats a commit as "abc1234 by Author, 3h ago"
+func describeCommit(commit *types.CommitInfo) string {
+	description := shortHash(commit.Hash)
+	if commit.Author != "" {
+		description += " by " + commit.Author
+	}
+	if !commit.Date.IsZero() {
+		description += ", " + describeAge(commit.Date)
+	}
+	return description
+}
+
+// describeAge formats how long ago t was in its largest unit, e.g. "3h ago"
+func describeAge(t time.Time) string {
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
+
+// shortHash abbreviates a commit hash for display
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// describeRemoteSync summarizes how HEAD compares with a remote's branch
+func describeRemoteSync(remote types.RemoteStatus, branch string) string {
+	ab := remote.AheadBehind
+	switch {
+	case ab == nil:
+		return fmt.Sprintf("no %s/%s (not fetched or missing)", remote.Name, branch)
+	case ab.Ahead == 0 && ab.Behind == 0:
+		return "up to date"
+	default:
+		return fmt.Sprintf("%d ahead, %d behind", ab.Ahead, ab.Behind)
+	}
+}
+
 // loadConfigWithVerbose loads configuration with details
 func (s *StatusCommand) loadConfigWithVerbose() (*config.ConfigLoadResult, error) {
 	loader := config.NewLoader()
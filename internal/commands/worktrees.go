@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/LederWorks/gorepos/internal/config"
+	"github.com/LederWorks/gorepos/internal/executor"
+	"github.com/LederWorks/gorepos/internal/repository"
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// Pool operations registered by the prune-worktrees command
+const (
+	opFindWorktrees  = "find-worktrees"
+	opRemoveWorktree = "remove-worktrees"
+)
+
+// PruneWorktreesCommand removes worktrees that are no longer in the configuration
+type PruneWorktreesCommand struct{}
+
+// NewPruneWorktreesCommand creates a new prune-worktrees command handler
+func NewPruneWorktreesCommand() *PruneWorktreesCommand {
+	return &PruneWorktreesCommand{}
+}
+
+// Execute finds worktrees git knows about that the configuration does not
+// declare and removes each one the user confirms. assumeYes skips the prompts.
+// Finding and removing run on the pool; prompts are asked in between.
+func (c *PruneWorktreesCommand) Execute(configFile string, verbose bool, workers int, dryRun, assumeYes bool) error {
+	configPath := configFile
+	if configPath == "" {
+		var err error
+		configPath, err = config.GetConfigPath()
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := config.NewLoader().LoadConfigWithDetails(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	cfg := result.Config
+
+	// Override workers from command line if provided
+	if workers > 0 {
+		cfg.Global.Workers = workers
+	}
+
+	ctx := context.Background()
+	repoManager := repository.NewManagerFromConfig(&cfg.Global)
+	exec := executor.NewManagedPool(cfg.Global.Workers, repoManager)
+	exec.SetTimeout(cfg.Global.Timeout)
+	exec.SetRetryPolicy(executor.NewRetryPolicy(cfg.Global.Retry))
+
+	var mu sync.Mutex
+	stray := make(map[*types.Repository][]types.WorktreeStatus)
+	exec.RegisterHandler(opFindWorktrees, func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		worktrees, err := repoManager.Worktrees(ctx, op.Repository)
+		if err != nil {
+			return err
+		}
+		var found []types.WorktreeStatus
+		for _, wt := range worktrees {
+			if !wt.Configured {
+				found = append(found, wt)
+			}
+		}
+		mu.Lock()
+		stray[op.Repository] = found
+		mu.Unlock()
+		return nil
+	})
+	exec.RegisterHandler(opRemoveWorktree, removeWorktreesHandler(repoManager))
+
+	fmt.Printf("GoRepos Prune Worktrees (workers: %d)\n", cfg.Global.Workers)
+	fmt.Println(strings.Repeat("=", 40))
+
+	var operations []types.Operation
+	for i := range cfg.Repositories {
+		repo := &cfg.Repositories[i]
+		if repo.Disabled || !repoManager.Exists(repo) {
+			continue
+		}
+		operations = append(operations, types.Operation{
+			Repository: repo,
+			Command:    opFindWorktrees,
+			Context:    ctx,
+		})
+	}
+
+	var summary executor.Summary
+	for result := range exec.Execute(ctx, operations) {
+		summary.Add(result)
+		if result.Error != nil {
+			fmt.Printf("%s: could not list worktrees: %v\n", result.Repository.Name, result.Error)
+		}
+	}
+
+	// Ask in configuration order, one repository at a time
+	reader := bufio.NewReader(os.Stdin)
+	var removals []types.Operation
+	found := 0
+	for _, op := range operations {
+		repo := op.Repository
+		var paths []string
+		for _, wt := range stray[repo] {
+			found++
+
+			if dryRun {
+				fmt.Printf("Would remove worktree %s (%s) from %s\n", wt.Path, wt.Branch, repo.Name)
+				continue
+			}
+			if !assumeYes && !confirm(reader, fmt.Sprintf("Remove worktree %s (%s) from %s? [y/N]: ", wt.Path, wt.Branch, repo.Name)) {
+				if verbose {
+					fmt.Printf("Kept %s\n", wt.Path)
+				}
+				continue
+			}
+			paths = append(paths, wt.Path)
+		}
+
+		if len(paths) > 0 {
+			removals = append(removals, types.Operation{
+				Repository: repo,
+				Command:    opRemoveWorktree,
+				Args:       paths,
+				Context:    ctx,
+			})
+		}
+	}
+
+	removed := 0
+	for result := range exec.Execute(ctx, removals) {
+		summary.Add(result)
+		for _, line := range strings.Split(result.Output, "\n") {
+			if line != "" {
+				removed++
+				fmt.Println(line)
+			}
+		}
+		if result.Error != nil {
+			fmt.Printf("Could not remove worktrees of %s: %v\n", result.Repository.Name, result.Error)
+		}
+	}
+
+	if found == 0 {
+		fmt.Println("No worktrees outside the configuration")
+	} else if !dryRun {
+		fmt.Printf("Removed %d of %d worktree(s) not in configuration\n", removed, found)
+	}
+
+	fmt.Println(strings.Repeat("=", 40))
+	fmt.Println(summary.String())
+
+	return exec.Shutdown(ctx)
+}
+
+// removeWorktreesHandler removes the worktrees at op.Args from the
+// operation's repository one after another, so git never prunes the same
+// repository concurrently. The output names each removed worktree on its own
+// line, including those removed before a failure.
+func removeWorktreesHandler(repoManager *repository.Manager) executor.Handler {
+	return func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		var removed []string
+		defer func() { result.Output = strings.Join(removed, "\n") }()
+
+		for _, path := range op.Args {
+			if err := repoManager.RemoveWorktree(ctx, op.Repository, path); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			removed = append(removed, "Removed worktree "+path)
+		}
+		return nil
+	}
+}
+
+// confirm asks a yes/no question and reports whether the answer was yes
+func confirm(reader *bufio.Reader, prompt string) bool {
+	fmt.Print(prompt)
+	input, _ := reader.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
@@ -182,8 +182,8 @@ func TestSetDefaults_FillsMissingFields(t *testing.T) {
 	if c.Global.Timeout != 5*time.Minute {
 		t.Errorf("expected 5m timeout, got %v", c.Global.Timeout)
 	}
-	if c.Repositories[0].Branch != "main" {
-		t.Errorf("expected branch 'main', got %q", c.Repositories[0].Branch)
+	if c.Repositories[0].Branch != "" {
+		t.Errorf("expected branch to be left for detection, got %q", c.Repositories[0].Branch)
 	}
 }
 
@@ -298,7 +298,7 @@ func TestMergeConfigs_ReposMainOverridesIncluded(t *testing.T) {
 	}
 }
 
-func TestMergeConfigs_EnvVarsMainTakesPrecedence(t *testing.T) {
+func TestMergeConfigs_EnvVarsStayInTheirFile(t *testing.T) {
 	l := newLoader()
 
 	main := &types.Config{
@@ -315,10 +315,10 @@ func TestMergeConfigs_EnvVarsMainTakesPrecedence(t *testing.T) {
 	result := l.mergeConfigs(main, included)
 
 	if result.Global.Environment["KEY"] != "main-value" {
-		t.Errorf("main env var should take precedence, got %q", result.Global.Environment["KEY"])
+		t.Errorf("main env var should be kept, got %q", result.Global.Environment["KEY"])
 	}
-	if result.Global.Environment["INCLUDED_ONLY"] != "yes" {
-		t.Errorf("expected included-only env var to be present")
+	if _, ok := result.Global.Environment["INCLUDED_ONLY"]; ok {
+		t.Errorf("included env var should not become global")
 	}
 	if result.Global.Environment["MAIN_ONLY"] != "yes" {
 		t.Errorf("expected main-only env var to be present")
@@ -67,10 +67,16 @@ func (l *Loader) LoadConfigWithDetails(path string) (*ConfigLoadResult, error) {
 	if err := l.ValidateConfig(config); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
 	}
+	if err := l.validateDependencies(config); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
 
 	// Apply final group inheritance for root-level empty groups after all merging is complete
 	l.applyRootGroupInheritance(config)
 
+	// Resolve group and tag priorities onto repositories
+	l.applyPriorities(config)
+
 	// Set default values after loading and merging
 	l.setDefaults(config)
 
@@ -117,6 +123,10 @@ func (l *Loader) loadConfigRecursiveWithHierarchy(path string, visited map[strin
 		return nil, node, fmt.Errorf("failed to parse YAML in %s: %w", path, err)
 	}
 
+	// Captured before includes are merged so this file's repository defaults
+	// reach only its own repositories and those of files it includes
+	fileGlobal := config.Global
+
 	// Validate the configuration using struct validation tags
 	if err := l.validatePartialConfig(&config); err != nil {
 		node.IsValid = false
@@ -128,6 +138,7 @@ func (l *Loader) loadConfigRecursiveWithHierarchy(path string, visited map[strin
 		repoInfo := RepositoryInfo{
 			Name:     repo.Name,
 			Disabled: repo.Disabled,
+			Branch:   repo.Branch,
 		}
 		node.Repositories = append(node.Repositories, repoInfo)
 	}
@@ -157,6 +168,7 @@ func (l *Loader) loadConfigRecursiveWithHierarchy(path string, visited map[strin
 				remoteNode.Repositories = append(remoteNode.Repositories, RepositoryInfo{
 					Name:     repo.Name,
 					Disabled: repo.Disabled,
+					Branch:   repo.Branch,
 				})
 			}
 			node.Includes = append(node.Includes, *remoteNode)
@@ -183,6 +195,9 @@ func (l *Loader) loadConfigRecursiveWithHierarchy(path string, visited map[strin
 		config = l.mergeConfigs(&config, includedConfig)
 	}
 
+	// Inherit this file's repository defaults now that includes are merged
+	l.applyFileDefaults(&config, fileGlobal)
+
 	// Set default values
 	l.setDefaults(&config)
 
@@ -212,6 +227,9 @@ func (l *Loader) LoadRemoteConfig(url string) (*types.Config, error) {
 		return nil, fmt.Errorf("failed to parse remote YAML config: %w", err)
 	}
 
+	// Remote configs have no includes, so the global settings apply directly
+	l.applyFileDefaults(&config, config.Global)
+
 	// Set default values
 	l.setDefaults(&config)
 
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/LederWorks/gorepos/pkg/types"
@@ -20,16 +21,28 @@ func (l *Loader) mergeConfigs(main *types.Config, included *types.Config) types.
 	if result.Global.BasePath == "" && included.Global.BasePath != "" {
 		result.Global.BasePath = included.Global.BasePath
 	}
-
-	// Merge environment variables
-	if result.Global.Environment == nil {
-		result.Global.Environment = make(map[string]string)
+	if result.Global.Retry == nil && included.Global.Retry != nil {
+		result.Global.Retry = included.Global.Retry
 	}
-	for key, value := range included.Global.Environment {
-		if _, exists := result.Global.Environment[key]; !exists {
-			result.Global.Environment[key] = value
-		}
+	if result.Global.HostLimits == nil && included.Global.HostLimits != nil {
+		result.Global.HostLimits = included.Global.HostLimits
+	}
+	if result.Global.Clone == nil && included.Global.Clone != nil {
+		result.Global.Clone = included.Global.Clone
+	}
+	if result.Global.UpdateStrategy == "" && included.Global.UpdateStrategy != "" {
+		result.Global.UpdateStrategy = included.Global.UpdateStrategy
 	}
+	if result.Global.Credentials == nil && included.Global.Credentials != nil {
+		result.Global.Credentials = included.Global.Credentials
+	}
+	if result.Global.Mode == "" && included.Global.Mode != "" {
+		result.Global.Mode = included.Global.Mode
+	}
+	result.Global.Priorities = mergePriorities(result.Global.Priorities, included.Global.Priorities)
+
+	// Environment variables are not merged: an included file's variables
+	// reach its own repositories through their scope layer only
 
 	// Merge repositories (included first, then main to allow overrides)
 	repoMap := make(map[string]types.Repository)
@@ -73,6 +86,131 @@ func (l *Loader) mergeConfigs(main *types.Config, included *types.Config) types.
 	return result
 }
 
+// mergePriorities combines group and tag priorities, keeping the main config's
+// value when both define the same key
+func mergePriorities(main, included *types.PriorityConfig) *types.PriorityConfig {
+	if included == nil {
+		return main
+	}
+	if main == nil {
+		main = &types.PriorityConfig{}
+	}
+	if main.Groups == nil {
+		main.Groups = make(map[string]int)
+	}
+	if main.Tags == nil {
+		main.Tags = make(map[string]int)
+	}
+
+	for group, priority := range included.Groups {
+		if _, exists := main.Groups[group]; !exists {
+			main.Groups[group] = priority
+		}
+	}
+	for tag, priority := range included.Tags {
+		if _, exists := main.Tags[tag]; !exists {
+			main.Tags[tag] = priority
+		}
+	}
+	return main
+}
+
+// applyPriorities sets each repository's priority to the highest of its own
+// priority and those of the groups it belongs to and the tags it carries. An
+// unset (zero) repository priority does not count, so a group or tag can also
+// lower priority.
+func (l *Loader) applyPriorities(config *types.Config) {
+	priorities := config.Global.Priorities
+	if priorities == nil {
+		return
+	}
+
+	groupsOf := make(map[string][]string)
+	for group := range priorities.Groups {
+		for _, name := range config.Groups[group] {
+			groupsOf[name] = append(groupsOf[name], group)
+		}
+	}
+
+	for i := range config.Repositories {
+		repo := &config.Repositories[i]
+
+		var candidates []int
+		if repo.Priority != 0 {
+			candidates = append(candidates, repo.Priority)
+		}
+		for _, group := range groupsOf[repo.Name] {
+			candidates = append(candidates, priorities.Groups[group])
+		}
+		for key, value := range repo.Tags {
+			for _, tag := range []string{key, fmt.Sprintf("%s=%v", key, value)} {
+				if priority, ok := priorities.Tags[tag]; ok {
+					candidates = append(candidates, priority)
+				}
+			}
+		}
+
+		for j, priority := range candidates {
+			if j == 0 || priority > repo.Priority {
+				repo.Priority = priority
+			}
+		}
+	}
+}
+
+// applyFileDefaults gives repositories the settings declared in the global
+// section of the file that defines or includes them: the update strategy and
+// mode when they have none, autostash and any clone options they do not set,
+// and environment variables for their scope layer. It runs once per file
+// after its includes are merged, so the nearest file wins.
+func (l *Loader) applyFileDefaults(config *types.Config, global types.GlobalConfig) {
+	for i := range config.Repositories {
+		repo := &config.Repositories[i]
+		if repo.UpdateStrategy == "" {
+			repo.UpdateStrategy = global.UpdateStrategy
+		}
+		if repo.Mode == "" {
+			repo.Mode = global.Mode
+		}
+		if repo.Autostash == nil {
+			repo.Autostash = global.Autostash
+		}
+		repo.Clone = mergeCloneOptions(repo.Clone, global.Clone)
+		for key, value := range global.Environment {
+			if _, ok := repo.ScopeEnvironment[key]; ok {
+				continue
+			}
+			if repo.ScopeEnvironment == nil {
+				repo.ScopeEnvironment = make(map[string]string)
+			}
+			repo.ScopeEnvironment[key] = value
+		}
+	}
+}
+
+// mergeCloneOptions fills the options a repository leaves unset from defaults
+func mergeCloneOptions(opts, defaults *types.CloneOptions) *types.CloneOptions {
+	if defaults == nil {
+		return opts
+	}
+	merged := *defaults
+	if opts != nil {
+		if opts.Depth != 0 {
+			merged.Depth = opts.Depth
+		}
+		if opts.Filter != "" {
+			merged.Filter = opts.Filter
+		}
+		if opts.SingleBranch != nil {
+			merged.SingleBranch = opts.SingleBranch
+		}
+		if opts.SparsePaths != nil {
+			merged.SparsePaths = opts.SparsePaths
+		}
+	}
+	return &merged
+}
+
 // applyRootGroupInheritance populates all empty groups with all repositories after full merge is complete
 func (l *Loader) applyRootGroupInheritance(config *types.Config) {
 	if config.Groups == nil {
@@ -103,13 +241,6 @@ func (l *Loader) setDefaults(config *types.Config) {
 		config.Global.Timeout = 30 * time.Second
 	}
 
-	// Set default branch for repositories if not specified
-	for i := range config.Repositories {
-		if config.Repositories[i].Branch == "" {
-			config.Repositories[i].Branch = "main"
-		}
-	}
-
 	// Initialize maps if they don't exist
 	if config.Groups == nil {
 		config.Groups = make(map[string][]string)
@@ -0,0 +1,283 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+func priorityConfig() *types.Config {
+	cfg := validConfig()
+	cfg.Repositories = []types.Repository{
+		{Name: "payment-service", Path: "payment", URL: "https://github.com/example/payment.git"},
+		{Name: "user-service", Path: "user", URL: "https://github.com/example/user.git", Tags: map[string]interface{}{"criticality": "high"}},
+		{Name: "docs", Path: "docs", URL: "https://github.com/example/docs.git", Priority: 5, Tags: map[string]interface{}{"archived": true}},
+		{Name: "tools", Path: "tools", URL: "https://github.com/example/tools.git"},
+	}
+	cfg.Groups = map[string][]string{
+		"critical_services": {"payment-service", "user-service"},
+		"backlog":           {"docs"},
+	}
+	cfg.Global.Priorities = &types.PriorityConfig{
+		Groups: map[string]int{"critical_services": 100, "backlog": -10},
+		Tags:   map[string]int{"criticality=high": 150, "archived": -20},
+	}
+	return cfg
+}
+
+func TestApplyPriorities(t *testing.T) {
+	cfg := priorityConfig()
+	newLoader().applyPriorities(cfg)
+
+	want := map[string]int{
+		"payment-service": 100, // group
+		"user-service":    150, // key=value tag beats group
+		"docs":            5,   // own priority beats lower group and tag
+		"tools":           0,   // nothing applies
+	}
+	for _, repo := range cfg.Repositories {
+		if repo.Priority != want[repo.Name] {
+			t.Errorf("%s: expected priority %d, got %d", repo.Name, want[repo.Name], repo.Priority)
+		}
+	}
+}
+
+func TestApplyPriorities_GroupCanLowerUnsetPriority(t *testing.T) {
+	cfg := priorityConfig()
+	cfg.Repositories[2].Priority = 0
+	newLoader().applyPriorities(cfg)
+
+	if cfg.Repositories[2].Priority != -10 {
+		t.Errorf("expected docs to take the backlog priority -10, got %d", cfg.Repositories[2].Priority)
+	}
+}
+
+func TestMergePriorities_MainWins(t *testing.T) {
+	main := &types.PriorityConfig{Groups: map[string]int{"a": 1}}
+	included := &types.PriorityConfig{
+		Groups: map[string]int{"a": 9, "b": 2},
+		Tags:   map[string]int{"tier": 3},
+	}
+
+	merged := mergePriorities(main, included)
+	if merged.Groups["a"] != 1 || merged.Groups["b"] != 2 || merged.Tags["tier"] != 3 {
+		t.Errorf("unexpected merge result: %+v", merged)
+	}
+	if mergePriorities(nil, nil) != nil {
+		t.Error("expected nil when neither config sets priorities")
+	}
+}
+
+func TestLoadConfigWithDetails_InheritsUpdateSettings(t *testing.T) {
+	dir := t.TempDir()
+
+	writeYAML(t, dir, "team.yaml", `
+global:
+  updateStrategy: rebase
+  autostash: true
+  mode: mirror
+repositories:
+  - name: team-repo
+    path: team-repo
+    url: https://github.com/example/team.git
+  - name: pinned-repo
+    path: pinned-repo
+    url: https://github.com/example/pinned.git
+    updateStrategy: reset
+    mode: checkout
+`)
+	writeYAML(t, dir, "other.yaml", `
+repositories:
+  - name: other-repo
+    path: other-repo
+    url: https://github.com/example/other.git
+`)
+	mainPath := writeYAML(t, dir, "main.yaml", `
+version: "1.0"
+global:
+  basePath: /tmp/repos
+  updateStrategy: merge
+includes:
+  - team.yaml
+  - other.yaml
+repositories:
+  - name: main-repo
+    path: main-repo
+    url: https://github.com/example/main.git
+`)
+
+	result, err := newLoader().LoadConfigWithDetails(mainPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]types.UpdateStrategy{
+		"main-repo":   types.StrategyMerge,
+		"team-repo":   types.StrategyRebase,
+		"pinned-repo": types.StrategyReset,
+		"other-repo":  types.StrategyMerge,
+	}
+	for _, repo := range result.Config.Repositories {
+		if repo.UpdateStrategy != want[repo.Name] {
+			t.Errorf("%s: expected strategy %q, got %q", repo.Name, want[repo.Name], repo.UpdateStrategy)
+		}
+		stashed := repo.Name == "team-repo" || repo.Name == "pinned-repo"
+		if repo.AutostashEnabled() != stashed {
+			t.Errorf("%s: expected autostash %v, got %v", repo.Name, stashed, repo.AutostashEnabled())
+		}
+	}
+
+	modes := map[string]types.CloneMode{"team-repo": types.ModeMirror, "pinned-repo": types.ModeCheckout}
+	for _, repo := range result.Config.Repositories {
+		if repo.Mode != modes[repo.Name] {
+			t.Errorf("%s: expected mode %q, got %q", repo.Name, modes[repo.Name], repo.Mode)
+		}
+	}
+}
+
+// boolPtr returns a pointer to v, for optional settings.
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func TestMergeCloneOptions(t *testing.T) {
+	defaults := &types.CloneOptions{Depth: 1, Filter: "blob:none", SparsePaths: []string{"src"}}
+
+	merged := mergeCloneOptions(&types.CloneOptions{Depth: 50, SingleBranch: boolPtr(true)}, defaults)
+	if merged.Depth != 50 || merged.Filter != "blob:none" || !merged.SingleBranchEnabled() || len(merged.SparsePaths) != 1 {
+		t.Errorf("expected repository options over defaults, got %+v", merged)
+	}
+	if merged == defaults {
+		t.Error("expected a copy, not the shared defaults")
+	}
+
+	if got := mergeCloneOptions(nil, nil); got != nil {
+		t.Errorf("expected nil without options, got %+v", got)
+	}
+}
+
+func TestMergeCloneOptions_RepositoryFalseOverridesDefault(t *testing.T) {
+	defaults := &types.CloneOptions{SingleBranch: boolPtr(true)}
+
+	if merged := mergeCloneOptions(&types.CloneOptions{SingleBranch: boolPtr(false)}, defaults); merged.SingleBranchEnabled() {
+		t.Error("expected the repository's singleBranch: false to win")
+	}
+	if merged := mergeCloneOptions(&types.CloneOptions{Depth: 1}, defaults); !merged.SingleBranchEnabled() {
+		t.Error("expected an unset singleBranch to inherit the default")
+	}
+}
+
+func TestLoadConfigWithDetails_RepositoryDisablesInheritedSettings(t *testing.T) {
+	dir := t.TempDir()
+
+	writeYAML(t, dir, "team.yaml", `
+global:
+  autostash: false
+repositories:
+  - name: team-repo
+    path: team-repo
+    url: https://github.com/example/team.git
+`)
+	mainPath := writeYAML(t, dir, "main.yaml", `
+version: "1.0"
+global:
+  basePath: /tmp/repos
+  autostash: true
+  clone:
+    singleBranch: true
+includes:
+  - team.yaml
+repositories:
+  - name: main-repo
+    path: main-repo
+    url: https://github.com/example/main.git
+  - name: opted-out
+    path: opted-out
+    url: https://github.com/example/opted-out.git
+    autostash: false
+    clone:
+      singleBranch: false
+`)
+
+	result, err := newLoader().LoadConfigWithDetails(mainPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]struct{ autostash, singleBranch bool }{
+		"main-repo": {true, true},
+		"opted-out": {false, false},
+		"team-repo": {false, true}, // The nearest file's autostash: false wins
+	}
+	for _, repo := range result.Config.Repositories {
+		w := want[repo.Name]
+		if repo.AutostashEnabled() != w.autostash {
+			t.Errorf("%s: expected autostash %v, got %v", repo.Name, w.autostash, repo.AutostashEnabled())
+		}
+		if repo.Clone == nil || repo.Clone.SingleBranchEnabled() != w.singleBranch {
+			t.Errorf("%s: expected singleBranch %v, got %+v", repo.Name, w.singleBranch, repo.Clone)
+		}
+	}
+}
+
+func TestLoadConfigWithDetails_ScopeEnvironment(t *testing.T) {
+	dir := t.TempDir()
+
+	writeYAML(t, dir, "team.yaml", `
+global:
+  environment:
+    SHARED: team
+repositories:
+  - name: team-repo
+    path: team-repo
+    url: https://github.com/example/team.git
+`)
+	writeYAML(t, dir, "other.yaml", `
+global:
+  environment:
+    OTHER_ONLY: other
+repositories:
+  - name: other-repo
+    path: other-repo
+    url: https://github.com/example/other.git
+`)
+	mainPath := writeYAML(t, dir, "main.yaml", `
+version: "1.0"
+global:
+  basePath: /tmp/repos
+  environment:
+    SHARED: main
+    ROOT_ONLY: root
+includes:
+  - team.yaml
+  - other.yaml
+repositories:
+  - name: main-repo
+    path: main-repo
+    url: https://github.com/example/main.git
+`)
+
+	result, err := newLoader().LoadConfigWithDetails(mainPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]map[string]string{
+		"main-repo":  {"SHARED": "main", "ROOT_ONLY": "root"},
+		"team-repo":  {"SHARED": "team", "ROOT_ONLY": "root"},
+		"other-repo": {"SHARED": "main", "ROOT_ONLY": "root", "OTHER_ONLY": "other"},
+	}
+	if _, ok := result.Config.Global.Environment["OTHER_ONLY"]; ok {
+		t.Error("an included file's environment should not become global")
+	}
+	for _, repo := range result.Config.Repositories {
+		for key, value := range want[repo.Name] {
+			if repo.ScopeEnvironment[key] != value {
+				t.Errorf("%s: expected %s=%s, got %q", repo.Name, key, value, repo.ScopeEnvironment[key])
+			}
+		}
+		if len(repo.ScopeEnvironment) != len(want[repo.Name]) {
+			t.Errorf("%s: unexpected scope environment %v", repo.Name, repo.ScopeEnvironment)
+		}
+	}
+}
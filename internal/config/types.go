@@ -18,6 +18,7 @@ type ConfigLoadResult struct {
 type RepositoryInfo struct {
 	Name     string
 	Disabled bool
+	Branch   string // Branch as configured in the file, empty when left to detection
 }
 
 // FileNode represents a configuration file in the include hierarchy
@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/LederWorks/gorepos/pkg/types"
@@ -29,6 +32,55 @@ func (l *Loader) ValidateConfig(config *types.Config) error {
 	if config.Global.Timeout < 0 {
 		return fmt.Errorf("timeout must be non-negative")
 	}
+	if retry := config.Global.Retry; retry != nil {
+		if retry.MaxAttempts < 0 || retry.MaxAttempts > 10 {
+			return fmt.Errorf("retry.maxAttempts must be between 1 and 10")
+		}
+		if retry.InitialBackoff < 0 || retry.MaxBackoff < 0 {
+			return fmt.Errorf("retry backoff durations must be non-negative")
+		}
+		for _, category := range retry.Categories {
+			if !isRetryableCategory(category) {
+				return fmt.Errorf("retry.categories: unknown error category %q", category)
+			}
+		}
+	}
+	if limits := config.Global.HostLimits; limits != nil {
+		if limits.Default < 0 {
+			return fmt.Errorf("hostLimits.default must be non-negative")
+		}
+		for host, limit := range limits.Hosts {
+			if limit < 0 {
+				return fmt.Errorf("hostLimits.hosts.%s must be non-negative", host)
+			}
+		}
+	}
+
+	if err := validateCloneOptions(config.Global.Clone); err != nil {
+		return fmt.Errorf("clone: %w", err)
+	}
+	if !isUpdateStrategy(config.Global.UpdateStrategy) {
+		return fmt.Errorf("unknown updateStrategy %q", config.Global.UpdateStrategy)
+	}
+	if !isCloneMode(config.Global.Mode) {
+		return fmt.Errorf("mode must be checkout or mirror, got %q", config.Global.Mode)
+	}
+	if creds := config.Global.Credentials; creds != nil {
+		if err := validateCredentials(*creds); err != nil {
+			return fmt.Errorf("credentials: %w", err)
+		}
+		for pattern, host := range creds.Hosts {
+			if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+				return fmt.Errorf("credentials.hosts: invalid host pattern %q", pattern)
+			}
+			if len(host.Hosts) > 0 {
+				return fmt.Errorf("credentials.hosts.%s: hosts cannot be nested", pattern)
+			}
+			if err := validateCredentials(host); err != nil {
+				return fmt.Errorf("credentials.hosts.%s: %w", pattern, err)
+			}
+		}
+	}
 
 	// Validate repositories (only if they exist)
 	if len(config.Repositories) > 0 {
@@ -55,6 +107,119 @@ func (l *Loader) ValidateConfig(config *types.Config) error {
 			if _, err := url.Parse(repo.URL); err != nil {
 				return fmt.Errorf("repository[%d]: invalid URL format: %w", i, err)
 			}
+
+			if repo.Timeout < 0 {
+				return fmt.Errorf("repository[%d]: timeout must be non-negative", i)
+			}
+			if !isUpdateStrategy(repo.UpdateStrategy) {
+				return fmt.Errorf("repository[%d]: unknown updateStrategy %q", i, repo.UpdateStrategy)
+			}
+			if repo.Submodules != "" && repo.Submodules != types.SubmodulesRecursive && repo.Submodules != types.SubmodulesNone {
+				return fmt.Errorf("repository[%d]: submodules must be recursive or none, got %q", i, repo.Submodules)
+			}
+			if err := validateCloneOptions(repo.Clone); err != nil {
+				return fmt.Errorf("repository[%d]: clone: %w", i, err)
+			}
+			worktreePaths := make(map[string]bool)
+			for j, wt := range repo.Worktrees {
+				if strings.TrimSpace(wt.Branch) == "" || strings.TrimSpace(wt.Path) == "" {
+					return fmt.Errorf("repository[%d]: worktrees[%d]: branch and path are required", i, j)
+				}
+				if worktreePaths[filepath.Clean(wt.Path)] || filepath.Clean(wt.Path) == filepath.Clean(repo.Path) {
+					return fmt.Errorf("repository[%d]: worktrees[%d]: path %s is already used", i, j, wt.Path)
+				}
+				worktreePaths[filepath.Clean(wt.Path)] = true
+			}
+			if err := validateRemotes(repo); err != nil {
+				return fmt.Errorf("repository[%d]: remotes: %w", i, err)
+			}
+			if !isCloneMode(repo.Mode) {
+				return fmt.Errorf("repository[%d]: mode must be checkout or mirror, got %q", i, repo.Mode)
+			}
+			if err := validateMirror(repo); err != nil {
+				return fmt.Errorf("repository[%d]: mode mirror: %w", i, err)
+			}
+			if err := validateEnvironment(config.Global.Environment, &repo); err != nil {
+				return fmt.Errorf("repository[%d]: %w", i, err)
+			}
+			if creds := repo.Credentials; creds != nil {
+				if len(creds.Hosts) > 0 {
+					return fmt.Errorf("repository[%d]: credentials: hosts can only be set globally", i)
+				}
+				if err := validateCredentials(*creds); err != nil {
+					return fmt.Errorf("repository[%d]: credentials: %w", i, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateRemotes checks remote names and URLs. origin may only repeat url.
+func validateRemotes(repo types.Repository) error {
+	for name, url := range repo.Remotes {
+		if name == "" || strings.ContainsAny(name, " \t/:") {
+			return fmt.Errorf("invalid remote name %q", name)
+		}
+		if strings.TrimSpace(url) == "" {
+			return fmt.Errorf("%s: url is required", name)
+		}
+		if name == "origin" && url != repo.URL {
+			return fmt.Errorf("origin must match the repository url; change url instead")
+		}
+	}
+	return nil
+}
+
+// validateDependencies checks dependsOn references on a fully merged configuration
+func (l *Loader) validateDependencies(config *types.Config) error {
+	repos := make(map[string]*types.Repository, len(config.Repositories))
+	for i := range config.Repositories {
+		repos[config.Repositories[i].Name] = &config.Repositories[i]
+	}
+
+	for _, repo := range config.Repositories {
+		for _, dep := range repo.DependsOn {
+			if dep == repo.Name {
+				return fmt.Errorf("repository %s: cannot depend on itself", repo.Name)
+			}
+			if _, exists := repos[dep]; !exists {
+				return fmt.Errorf("repository %s: depends on unknown repository %s", repo.Name, dep)
+			}
+		}
+	}
+
+	// Detect cycles with a depth-first search over dependsOn edges
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(repos))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("dependency cycle detected: %s", strings.Join(append(path, name), " -> "))
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		for _, dep := range repos[name].DependsOn {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+
+	for _, repo := range config.Repositories {
+		if err := visit(repo.Name, nil); err != nil {
+			return err
 		}
 	}
 
@@ -109,3 +274,93 @@ func (l *Loader) validatePartialConfig(config *types.Config) error {
 
 	return nil
 }
+
+// isRetryableCategory reports whether a category may be listed in retry.categories
+func isRetryableCategory(category types.ErrorCategory) bool {
+	switch category {
+	case types.ErrorNetwork, types.ErrorAuth, types.ErrorNotFound,
+		types.ErrorDirty, types.ErrorConflict, types.ErrorTimeout, types.ErrorUnknown,
+		types.ErrorAhead, types.ErrorDiverged, types.ErrorWrongBranch:
+		return true
+	}
+	return false
+}
+
+// isUpdateStrategy reports whether strategy is empty or a known update strategy
+func isUpdateStrategy(strategy types.UpdateStrategy) bool {
+	switch strategy {
+	case "", types.StrategyFFOnly, types.StrategyRebase, types.StrategyMerge, types.StrategyReset:
+		return true
+	}
+	return false
+}
+
+// validateEnvironment checks that every environment value reaching the
+// repository expands as a template over it
+func validateEnvironment(global map[string]string, repo *types.Repository) error {
+	for _, layer := range []map[string]string{global, repo.ScopeEnvironment, repo.Environment} {
+		for name, value := range layer {
+			if _, err := types.RenderEnvTemplate(value, repo); err != nil {
+				return fmt.Errorf("environment %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// envVarName matches valid environment variable names
+var envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateCredentials checks one level of credential settings
+func validateCredentials(creds types.CredentialConfig) error {
+	if creds.TokenEnvVar != "" && !envVarName.MatchString(creds.TokenEnvVar) {
+		return fmt.Errorf("tokenEnvVar %q is not a valid environment variable name", creds.TokenEnvVar)
+	}
+	if strings.Contains(creds.TokenUser, ":") {
+		return fmt.Errorf("tokenUser cannot contain ':'")
+	}
+	return nil
+}
+
+// isCloneMode reports whether mode is empty or a known clone mode
+func isCloneMode(mode types.CloneMode) bool {
+	return mode == "" || mode == types.ModeCheckout || mode == types.ModeMirror
+}
+
+// validateMirror rejects settings that need a working tree on mirror clones
+func validateMirror(repo types.Repository) error {
+	if repo.Mode != types.ModeMirror {
+		return nil
+	}
+	switch {
+	case len(repo.Worktrees) > 0:
+		return fmt.Errorf("worktrees need a working tree")
+	case repo.Submodules == types.SubmodulesRecursive:
+		return fmt.Errorf("submodules need a working tree")
+	case repo.LFS:
+		return fmt.Errorf("lfs needs a working tree")
+	case repo.Clone != nil && len(repo.Clone.SparsePaths) > 0:
+		return fmt.Errorf("sparse paths need a working tree")
+	}
+	return nil
+}
+
+// validateCloneOptions checks clone options; sparse paths must stay inside the repository
+func validateCloneOptions(opts *types.CloneOptions) error {
+	if opts == nil {
+		return nil
+	}
+	if opts.Depth < 0 {
+		return fmt.Errorf("depth must be non-negative")
+	}
+	if strings.ContainsAny(opts.Filter, " \t") {
+		return fmt.Errorf("invalid filter %q", opts.Filter)
+	}
+	for _, path := range opts.SparsePaths {
+		clean := filepath.ToSlash(filepath.Clean(path))
+		if path == "" || filepath.IsAbs(path) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return fmt.Errorf("sparse path %q must be relative to the repository root", path)
+		}
+	}
+	return nil
+}
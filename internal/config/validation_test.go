@@ -0,0 +1,236 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+func dependencyConfig(deps map[string][]string) *types.Config {
+	cfg := validConfig()
+	cfg.Repositories = nil
+	for _, name := range []string{"lib", "svc", "web"} {
+		cfg.Repositories = append(cfg.Repositories, types.Repository{
+			Name:      name,
+			Path:      name,
+			URL:       "https://github.com/example/" + name + ".git",
+			DependsOn: deps[name],
+		})
+	}
+	return cfg
+}
+
+func TestValidateDependencies_Valid(t *testing.T) {
+	cfg := dependencyConfig(map[string][]string{
+		"svc": {"lib"},
+		"web": {"svc", "lib"},
+	})
+	if err := newLoader().validateDependencies(cfg); err != nil {
+		t.Errorf("expected valid dependencies, got: %v", err)
+	}
+}
+
+func TestValidateDependencies_Unknown(t *testing.T) {
+	cfg := dependencyConfig(map[string][]string{"svc": {"missing"}})
+	err := newLoader().validateDependencies(cfg)
+	if err == nil || !strings.Contains(err.Error(), "unknown repository missing") {
+		t.Errorf("expected unknown dependency error, got: %v", err)
+	}
+}
+
+func TestValidateDependencies_Self(t *testing.T) {
+	cfg := dependencyConfig(map[string][]string{"svc": {"svc"}})
+	if err := newLoader().validateDependencies(cfg); err == nil {
+		t.Error("expected self-dependency error")
+	}
+}
+
+func TestValidateDependencies_Cycle(t *testing.T) {
+	cfg := dependencyConfig(map[string][]string{
+		"lib": {"web"},
+		"svc": {"lib"},
+		"web": {"svc"},
+	})
+	err := newLoader().validateDependencies(cfg)
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle") {
+		t.Errorf("expected cycle error, got: %v", err)
+	}
+}
+
+func TestValidateConfig_HostLimits(t *testing.T) {
+	cfg := validConfig()
+	cfg.Global.HostLimits = &types.HostLimitConfig{Default: 4, Hosts: map[string]int{"github.com": 8}}
+	if err := newLoader().ValidateConfig(cfg); err != nil {
+		t.Errorf("expected valid host limits, got: %v", err)
+	}
+
+	cfg.Global.HostLimits.Hosts["gitlab.com"] = -1
+	err := newLoader().ValidateConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "hostLimits.hosts.gitlab.com") {
+		t.Errorf("expected negative host limit error, got: %v", err)
+	}
+}
+
+func TestValidateConfig_UpdateStrategy(t *testing.T) {
+	cfg := validConfig()
+	cfg.Global.UpdateStrategy = types.StrategyRebase
+	cfg.Repositories[0].UpdateStrategy = types.StrategyReset
+	if err := newLoader().ValidateConfig(cfg); err != nil {
+		t.Errorf("expected valid update strategies, got: %v", err)
+	}
+
+	cfg.Repositories[0].UpdateStrategy = "squash"
+	err := newLoader().ValidateConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "updateStrategy") {
+		t.Errorf("expected unknown strategy error, got: %v", err)
+	}
+}
+
+func TestValidateConfig_CloneOptions(t *testing.T) {
+	cfg := validConfig()
+	cfg.Global.Clone = &types.CloneOptions{Depth: 1, Filter: "blob:none"}
+	cfg.Repositories[0].Clone = &types.CloneOptions{SparsePaths: []string{"services/api", "libs"}}
+	if err := newLoader().ValidateConfig(cfg); err != nil {
+		t.Errorf("expected valid clone options, got: %v", err)
+	}
+
+	cfg.Repositories[0].Clone.SparsePaths = []string{"../outside"}
+	err := newLoader().ValidateConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "sparse path") {
+		t.Errorf("expected sparse path error, got: %v", err)
+	}
+
+	cfg.Repositories[0].Clone = nil
+	cfg.Global.Clone.Depth = -1
+	if err := newLoader().ValidateConfig(cfg); err == nil {
+		t.Error("expected negative depth error")
+	}
+}
+
+func TestValidateConfig_Submodules(t *testing.T) {
+	cfg := validConfig()
+	cfg.Repositories[0].Submodules = types.SubmodulesRecursive
+	cfg.Repositories[0].LFS = true
+	if err := newLoader().ValidateConfig(cfg); err != nil {
+		t.Errorf("expected valid submodule settings, got: %v", err)
+	}
+
+	cfg.Repositories[0].Submodules = "shallow"
+	err := newLoader().ValidateConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "submodules") {
+		t.Errorf("expected submodules error, got: %v", err)
+	}
+}
+
+func TestValidateConfig_Worktrees(t *testing.T) {
+	cfg := validConfig()
+	cfg.Repositories[0].Worktrees = []types.Worktree{
+		{Branch: "release", Path: "repo1-release"},
+		{Branch: "hotfix", Path: "repo1-hotfix"},
+	}
+	if err := newLoader().ValidateConfig(cfg); err != nil {
+		t.Errorf("expected valid worktrees, got: %v", err)
+	}
+
+	cfg.Repositories[0].Worktrees[1].Path = "repo1-release/"
+	err := newLoader().ValidateConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "already used") {
+		t.Errorf("expected duplicate path error, got: %v", err)
+	}
+
+	cfg.Repositories[0].Worktrees[1] = types.Worktree{Path: "repo1-hotfix"}
+	if err := newLoader().ValidateConfig(cfg); err == nil {
+		t.Error("expected missing branch error")
+	}
+}
+
+func TestValidateConfig_Remotes(t *testing.T) {
+	cfg := validConfig()
+	cfg.Repositories[0].Remotes = map[string]string{
+		"upstream": "https://github.com/upstream/repo1.git",
+		"origin":   cfg.Repositories[0].URL,
+	}
+	if err := newLoader().ValidateConfig(cfg); err != nil {
+		t.Errorf("expected valid remotes, got: %v", err)
+	}
+
+	cfg.Repositories[0].Remotes["origin"] = "https://github.com/other/repo1.git"
+	if err := newLoader().ValidateConfig(cfg); err == nil || !strings.Contains(err.Error(), "origin") {
+		t.Errorf("expected origin mismatch error, got: %v", err)
+	}
+
+	cfg.Repositories[0].Remotes = map[string]string{"up stream": "https://github.com/upstream/repo1.git"}
+	if err := newLoader().ValidateConfig(cfg); err == nil {
+		t.Error("expected invalid remote name error")
+	}
+
+	cfg.Repositories[0].Remotes = map[string]string{"upstream": ""}
+	if err := newLoader().ValidateConfig(cfg); err == nil {
+		t.Error("expected missing url error")
+	}
+}
+
+func TestValidateConfig_Mode(t *testing.T) {
+	cfg := validConfig()
+	cfg.Global.Mode = types.ModeMirror
+	cfg.Repositories[0].Mode = types.ModeCheckout
+	if err := newLoader().ValidateConfig(cfg); err != nil {
+		t.Errorf("expected valid modes, got: %v", err)
+	}
+
+	cfg.Repositories[0].Mode = "bare"
+	if err := newLoader().ValidateConfig(cfg); err == nil {
+		t.Error("expected unknown mode error")
+	}
+
+	cfg.Repositories[0].Mode = types.ModeMirror
+	cfg.Repositories[0].Worktrees = []types.Worktree{{Branch: "release", Path: "repo1-release"}}
+	err := newLoader().ValidateConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "worktrees") {
+		t.Errorf("expected mirror worktrees error, got: %v", err)
+	}
+}
+
+func TestValidateConfig_Credentials(t *testing.T) {
+	cfg := validConfig()
+	cfg.Global.Credentials = &types.CredentialConfig{
+		TokenEnvVar: "GITHUB_TOKEN",
+		Hosts:       map[string]types.CredentialConfig{"*.example.com": {SSHKeyPath: "~/.ssh/work"}},
+	}
+	cfg.Repositories[0].Credentials = &types.CredentialConfig{GitCredHelper: "store"}
+	if err := newLoader().ValidateConfig(cfg); err != nil {
+		t.Errorf("expected valid credentials, got: %v", err)
+	}
+
+	cfg.Global.Credentials.Hosts["[bad"] = types.CredentialConfig{}
+	if err := newLoader().ValidateConfig(cfg); err == nil || !strings.Contains(err.Error(), "host pattern") {
+		t.Errorf("expected invalid pattern error, got: %v", err)
+	}
+	delete(cfg.Global.Credentials.Hosts, "[bad")
+
+	cfg.Repositories[0].Credentials.TokenEnvVar = "NOT-A-VAR"
+	if err := newLoader().ValidateConfig(cfg); err == nil || !strings.Contains(err.Error(), "tokenEnvVar") {
+		t.Errorf("expected invalid tokenEnvVar error, got: %v", err)
+	}
+
+	cfg.Repositories[0].Credentials = &types.CredentialConfig{Hosts: map[string]types.CredentialConfig{"github.com": {}}}
+	if err := newLoader().ValidateConfig(cfg); err == nil {
+		t.Error("expected repository hosts error")
+	}
+}
+
+func TestValidateConfig_EnvironmentTemplates(t *testing.T) {
+	cfg := validConfig()
+	cfg.Global.Environment = map[string]string{"CACHE": "/cache/{{ .Name }}"}
+	cfg.Repositories[0].Environment = map[string]string{"BIN": "{{ .Path }}/bin"}
+	if err := newLoader().ValidateConfig(cfg); err != nil {
+		t.Errorf("expected valid templates, got: %v", err)
+	}
+
+	cfg.Repositories[0].Environment["BAD"] = "{{ .Nmae }}"
+	err := newLoader().ValidateConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "environment BAD") {
+		t.Errorf("expected template error, got: %v", err)
+	}
+}
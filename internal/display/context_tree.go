@@ -95,7 +95,7 @@ func (d *ConfigTreeDisplay) printNodeContext(node FileNode, prefix string, isLas
 				statusSymbol = "○"
 			}
 
-			fmt.Printf("%s%s%s %s\n", repoPrefix, repoConnector, statusSymbol, repo.Name)
+			fmt.Printf("%s%s%s %s\n", repoPrefix, repoConnector, statusSymbol, repo.label())
 		}
 	}
 
@@ -191,7 +191,7 @@ func (d *ConfigTreeDisplay) printNodeWithValidationContext(node FileNode, prefix
 				statusSymbol = "○"
 			}
 
-			fmt.Printf("%s%s%s %s\n", repoPrefix, repoConnector, statusSymbol, repo.Name)
+			fmt.Printf("%s%s%s %s\n", repoPrefix, repoConnector, statusSymbol, repo.label())
 		}
 	}
 
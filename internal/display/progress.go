@@ -0,0 +1,206 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/LederWorks/gorepos/internal/executor"
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// progressRefresh is how often the live view is redrawn between events
+const progressRefresh = 200 * time.Millisecond
+
+// ResultFormatter renders a finished operation as a single line
+type ResultFormatter func(result types.Result) string
+
+// ProgressDisplay renders executor events. On a terminal it keeps a live view
+// of the active workers below the finished lines; otherwise it prints one
+// line per event.
+type ProgressDisplay struct {
+	out    io.Writer
+	tty    bool
+	format ResultFormatter
+
+	start    time.Time
+	total    int
+	queued   int
+	finished int
+	active   map[int]*activeOperation
+	drawn    int // lines in the live view currently on screen
+	done     chan struct{}
+}
+
+// activeOperation tracks what a worker is doing
+type activeOperation struct {
+	name      string
+	operation string
+	started   time.Time
+	message   string
+}
+
+// NewProgressDisplay creates a progress display writing to out. When tty is
+// false, output is plain text suitable for logs and pipes.
+func NewProgressDisplay(out io.Writer, tty bool, format ResultFormatter) *ProgressDisplay {
+	if format == nil {
+		format = defaultResultFormat
+	}
+	return &ProgressDisplay{
+		out:    out,
+		tty:    tty,
+		format: format,
+		start:  time.Now(),
+		active: make(map[int]*activeOperation),
+		done:   make(chan struct{}),
+	}
+}
+
+// IsTerminal reports whether f is an interactive terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Start renders events in the background until the channel is closed
+func (d *ProgressDisplay) Start(events <-chan executor.Event) {
+	go d.run(events)
+}
+
+// Wait blocks until the event channel has been closed and fully rendered
+func (d *ProgressDisplay) Wait() {
+	<-d.done
+}
+
+// run consumes events and refreshes the live view
+func (d *ProgressDisplay) run(events <-chan executor.Event) {
+	defer close(d.done)
+
+	var tick <-chan time.Time
+	if d.tty {
+		ticker := time.NewTicker(progressRefresh)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				d.clear()
+				return
+			}
+			d.handle(event)
+		case <-tick:
+			d.redraw()
+		}
+	}
+}
+
+// handle applies a single event to the display state
+func (d *ProgressDisplay) handle(event executor.Event) {
+	if event.Total > d.total {
+		d.total = event.Total
+	}
+	name := repositoryName(event.Repository)
+
+	switch event.Type {
+	case executor.EventQueued:
+		d.queued++
+		if !d.tty && d.queued == d.total {
+			fmt.Fprintf(d.out, "Queued %d operations\n", d.total)
+		}
+
+	case executor.EventStarted:
+		d.active[event.Worker] = &activeOperation{
+			name:      name,
+			operation: event.Operation,
+			started:   event.Time,
+		}
+		if !d.tty {
+			fmt.Fprintf(d.out, "%s %s: %s started on worker %d\n", d.counter(), name, event.Operation, event.Worker+1)
+		}
+
+	case executor.EventProgress:
+		if op, ok := d.active[event.Worker]; ok {
+			op.message = event.Message
+		}
+		if !d.tty {
+			fmt.Fprintf(d.out, "%s %s: %s\n", d.counter(), name, event.Message)
+		}
+
+	case executor.EventFinished:
+		d.finished++
+		delete(d.active, event.Worker)
+		d.clear()
+		fmt.Fprintf(d.out, "%s %s\n", d.counter(), d.format(*event.Result))
+	}
+
+	d.redraw()
+}
+
+// counter returns the overall "[finished/total]" prefix
+func (d *ProgressDisplay) counter() string {
+	width := len(fmt.Sprint(d.total))
+	return fmt.Sprintf("[%*d/%d]", width, d.finished, d.total)
+}
+
+// redraw replaces the live view with the current worker state
+func (d *ProgressDisplay) redraw() {
+	if !d.tty {
+		return
+	}
+	d.clear()
+
+	lines := []string{fmt.Sprintf("%s %s elapsed, %d active", d.counter(), time.Since(d.start).Round(time.Second), len(d.active))}
+
+	ids := make([]int, 0, len(d.active))
+	for id := range d.active {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		op := d.active[id]
+		line := fmt.Sprintf("  worker %d: %s (%s) %s", id+1, op.name, op.operation, time.Since(op.started).Round(100*time.Millisecond))
+		if op.message != "" {
+			line += " - " + op.message
+		}
+		lines = append(lines, line)
+	}
+
+	fmt.Fprint(d.out, strings.Join(lines, "\n")+"\n")
+	d.drawn = len(lines)
+}
+
+// clear erases the live view from the terminal
+func (d *ProgressDisplay) clear() {
+	if d.drawn == 0 {
+		return
+	}
+	fmt.Fprint(d.out, strings.Repeat("\033[1A\033[2K", d.drawn))
+	d.drawn = 0
+}
+
+// repositoryName returns a printable name for an event's repository
+func repositoryName(repo *types.Repository) string {
+	if repo == nil {
+		return "-"
+	}
+	return repo.Name
+}
+
+// defaultResultFormat renders a result as "<repo> <operation>: OK|<error>"
+func defaultResultFormat(result types.Result) string {
+	name := repositoryName(result.Repository)
+	if result.Error != nil {
+		return fmt.Sprintf("%s %s: %v", name, result.Operation, result.Error)
+	}
+	return fmt.Sprintf("%s %s: OK", name, result.Operation)
+}
@@ -1,5 +1,7 @@
 package display
 
+import "fmt"
+
 // ConfigTreeDisplay handles all configuration tree display functionality
 type ConfigTreeDisplay struct{}
 
@@ -19,6 +21,27 @@ type FileNode struct {
 
 // RepositoryInfo tracks repository name and status
 type RepositoryInfo struct {
-	Name     string
-	Disabled bool
+	Name         string
+	Disabled     bool
+	Branch       string // Tracked branch, empty when not yet detected
+	BranchSource string // "configured" or "detected"; empty hides the branch
+	State        string // Short working tree summary, e.g. "clean"; empty hides it
+}
+
+// label returns the repository name annotated with its branch and where the
+// branch came from, when known, followed by its working tree state
+func (r RepositoryInfo) label() string {
+	var label string
+	switch {
+	case r.BranchSource == "":
+		label = r.Name
+	case r.Branch == "":
+		label = r.Name + " [default branch not yet detected]"
+	default:
+		label = fmt.Sprintf("%s [%s, %s]", r.Name, r.Branch, r.BranchSource)
+	}
+	if r.State != "" {
+		label += " (" + r.State + ")"
+	}
+	return label
 }
@@ -63,7 +63,7 @@ func (d *ConfigTreeDisplay) printNodeWithValidation(node FileNode, prefix string
 				statusSymbol = "○"
 			}
 
-			fmt.Printf("%s%s%s %s\n", repoPrefix, repoConnector, statusSymbol, repo.Name)
+			fmt.Printf("%s%s%s %s\n", repoPrefix, repoConnector, statusSymbol, repo.label())
 		}
 	}
 
@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"time"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// EventType identifies a point in an operation's lifecycle
+type EventType string
+
+const (
+	EventQueued   EventType = "queued"
+	EventStarted  EventType = "started"
+	EventProgress EventType = "progress"
+	EventFinished EventType = "finished"
+)
+
+// Event describes a lifecycle change of a single operation
+type Event struct {
+	Type       EventType
+	Time       time.Time
+	Worker     int // Worker running the operation, -1 when not on a worker
+	Repository *types.Repository
+	Operation  string
+	Message    string        // Progress text for EventProgress
+	Result     *types.Result // Final result for EventFinished
+	Total      int           // Number of operations in the batch
+}
+
+// eventBuffer is the channel capacity given to each subscriber
+const eventBuffer = 256
+
+// Subscribe returns a channel receiving events for every batch the pool runs,
+// and a function that ends the subscription and closes the channel.
+// Subscribers must keep draining the channel until it is closed.
+func (p *Pool) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, eventBuffer)
+
+	p.subMu.Lock()
+	p.subscribers = append(p.subscribers, ch)
+	p.subMu.Unlock()
+
+	unsubscribe := func() {
+		p.subMu.Lock()
+		defer p.subMu.Unlock()
+
+		for i, sub := range p.subscribers {
+			if sub == ch {
+				p.subscribers = append(p.subscribers[:i], p.subscribers[i+1:]...)
+				close(ch)
+				return
+			}
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+// emit delivers an event to all current subscribers
+func (p *Pool) emit(event Event) {
+	p.subMu.Lock()
+	defer p.subMu.Unlock()
+
+	if len(p.subscribers) == 0 {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	for _, sub := range p.subscribers {
+		sub <- event
+	}
+}
+
+// hasSubscribers reports whether anyone is listening for events
+func (p *Pool) hasSubscribers() bool {
+	p.subMu.Lock()
+	defer p.subMu.Unlock()
+	return len(p.subscribers) > 0
+}
@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// collectEvents runs ops on the pool and returns every event it emitted
+func collectEvents(p *Pool, ops []types.Operation) []Event {
+	events, unsubscribe := p.Subscribe()
+
+	collected := make(chan []Event)
+	go func() {
+		var all []Event
+		for event := range events {
+			all = append(all, event)
+		}
+		collected <- all
+	}()
+
+	for range p.Execute(context.Background(), ops) {
+	}
+	unsubscribe()
+
+	return <-collected
+}
+
+func TestSubscribe_EmitsLifecycleEvents(t *testing.T) {
+	p := NewPool(2)
+	p.RegisterHandler("build", func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		types.ReportProgress(ctx, "compiling")
+		return nil
+	})
+
+	ops := []types.Operation{
+		makeOp(makeRepo("a"), "build"),
+		makeOp(makeRepo("b"), "build"),
+	}
+	events := collectEvents(p, ops)
+
+	counts := make(map[EventType]int)
+	for _, event := range events {
+		counts[event.Type]++
+		if event.Total != 2 {
+			t.Errorf("expected total 2 on %s event, got %d", event.Type, event.Total)
+		}
+		switch event.Type {
+		case EventProgress:
+			if event.Message != "compiling" {
+				t.Errorf("expected progress message 'compiling', got %q", event.Message)
+			}
+		case EventFinished:
+			if event.Result == nil || !event.Result.Success {
+				t.Errorf("expected successful result on finished event, got %+v", event.Result)
+			}
+		}
+	}
+
+	for _, eventType := range []EventType{EventQueued, EventStarted, EventProgress, EventFinished} {
+		if counts[eventType] != 2 {
+			t.Errorf("expected 2 %s events, got %d", eventType, counts[eventType])
+		}
+	}
+}
+
+func TestSubscribe_StartedPrecedesFinishedPerWorker(t *testing.T) {
+	p := NewPool(1)
+	p.RegisterHandler("build", func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		return nil
+	})
+
+	ops := []types.Operation{
+		makeOp(makeRepo("a"), "build"),
+		makeOp(makeRepo("b"), "build"),
+		makeOp(makeRepo("c"), "build"),
+	}
+
+	running := ""
+	for _, event := range collectEvents(p, ops) {
+		switch event.Type {
+		case EventStarted:
+			if running != "" {
+				t.Fatalf("worker started %s while %s was still running", event.Repository.Name, running)
+			}
+			running = event.Repository.Name
+		case EventFinished:
+			if event.Repository.Name != running {
+				t.Fatalf("finished %s while %s was running", event.Repository.Name, running)
+			}
+			running = ""
+		}
+	}
+}
+
+func TestSubscribe_SkippedOperationsFinishWithoutWorker(t *testing.T) {
+	p := NewPool(1)
+	p.RegisterHandler("build", func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		return errors.New("failed")
+	})
+	p.OrderByDependencies("build")
+
+	ops := []types.Operation{
+		makeOp(makeDepRepo("lib"), "build"),
+		makeOp(makeDepRepo("app", "lib"), "build"),
+	}
+
+	for _, event := range collectEvents(p, ops) {
+		if event.Repository.Name != "app" {
+			continue
+		}
+		if event.Type == EventStarted {
+			t.Error("expected skipped operation never to start")
+		}
+		if event.Type == EventFinished && (event.Worker != -1 || event.Result.State != types.ResultSkipped) {
+			t.Errorf("expected skipped finish without worker, got worker %d state %q", event.Worker, event.Result.State)
+		}
+	}
+}
+
+func TestSubscribe_UnsubscribeClosesChannel(t *testing.T) {
+	p := NewPool(1)
+	events, unsubscribe := p.Subscribe()
+	unsubscribe()
+	unsubscribe()
+
+	if _, ok := <-events; ok {
+		t.Error("expected channel to be closed after unsubscribe")
+	}
+	if p.hasSubscribers() {
+		t.Error("expected no subscribers after unsubscribe")
+	}
+}
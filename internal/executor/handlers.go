@@ -0,0 +1,134 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// Handler executes a single operation and records its outcome on the result.
+// The pool fills in the repository, operation name and timing; a handler only
+// needs to set Output/Status and return an error on failure.
+type Handler func(ctx context.Context, op *types.Operation, result *types.Result) error
+
+// Built-in operation names
+const (
+	OpClone  = "clone"
+	OpUpdate = "update"
+	OpStatus = "status"
+	OpExec   = "exec"
+)
+
+// NewManagedPool creates a pool with the built-in repository handlers registered
+func NewManagedPool(workerCount int, manager types.RepositoryManager) *Pool {
+	p := NewPool(workerCount)
+	p.RegisterRepositoryHandlers(manager)
+	return p
+}
+
+// RegisterHandler registers the handler for an operation name, replacing any existing one
+func (p *Pool) RegisterHandler(command string, handler Handler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.handlers == nil {
+		p.handlers = make(map[string]Handler)
+	}
+	p.handlers[command] = handler
+}
+
+// RegisterRepositoryHandlers registers clone, update, status and exec handlers backed by
+// the manager. Clone and update are network operations subject to host limits
+// and wait for their repository's dependencies.
+func (p *Pool) RegisterRepositoryHandlers(manager types.RepositoryManager) {
+	p.RegisterNetworkHandler(OpClone, cloneHandler(manager))
+	p.RegisterNetworkHandler(OpUpdate, updateHandler(manager))
+	p.OrderByDependencies(OpClone, OpUpdate)
+	p.RegisterHandler(OpStatus, statusHandler(manager))
+	p.RegisterHandler(OpExec, ExecHandler(manager, nil))
+}
+
+// getHandler returns the handler registered for an operation name
+func (p *Pool) getHandler(command string) (Handler, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	handler, ok := p.handlers[command]
+	return handler, ok
+}
+
+// cloneHandler clones the operation's repository
+func cloneHandler(manager types.RepositoryManager) Handler {
+	return func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		if err := manager.Clone(ctx, op.Repository); err != nil {
+			return err
+		}
+		result.Output = fmt.Sprintf("Cloned %s to %s", op.Repository.URL, op.Repository.Path)
+		return nil
+	}
+}
+
+// updateHandler updates the operation's repository
+func updateHandler(manager types.RepositoryManager) Handler {
+	return func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		report, err := manager.Update(ctx, op.Repository)
+		if err != nil {
+			return err
+		}
+		result.Update = report
+		result.Output = fmt.Sprintf("Updated repository at %s (%s)", op.Repository.Path, report)
+		return nil
+	}
+}
+
+// statusHandler collects the status of the operation's repository
+func statusHandler(manager types.RepositoryManager) Handler {
+	return func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		status, err := manager.Status(ctx, op.Repository)
+		if err != nil {
+			return err
+		}
+		result.Status = status
+		return nil
+	}
+}
+
+// LineFunc receives one line of an operation's output as it is written
+type LineFunc func(op *types.Operation, line string)
+
+// streamingManager is a RepositoryManager that can pass a command's output
+// on line by line while it runs
+type streamingManager interface {
+	ExecuteStream(ctx context.Context, repo *types.Repository, onLine func(line string), command string, args ...string) (*types.Result, error)
+}
+
+// ExecHandler runs op.Args[0] with the remaining args inside the repository.
+// When onLine is set and the manager can stream, each output line is also
+// passed to onLine as it is written.
+func ExecHandler(manager types.RepositoryManager, onLine LineFunc) Handler {
+	return func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		if len(op.Args) == 0 {
+			return fmt.Errorf("exec operation requires a command")
+		}
+
+		var execResult *types.Result
+		var err error
+		if streamer, ok := manager.(streamingManager); ok && onLine != nil {
+			execResult, err = streamer.ExecuteStream(ctx, op.Repository, func(line string) { onLine(op, line) }, op.Args[0], op.Args[1:]...)
+		} else {
+			execResult, err = manager.Execute(ctx, op.Repository, op.Args[0], op.Args[1:]...)
+		}
+		if execResult != nil {
+			result.Output = execResult.Output
+			result.ExitCode = execResult.ExitCode
+		}
+		if err != nil {
+			return err
+		}
+		if execResult != nil && execResult.Error != nil {
+			return execResult.Error
+		}
+		return nil
+	}
+}
@@ -0,0 +1,205 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// fakeManager is a RepositoryManager that records calls instead of running git
+type fakeManager struct {
+	mu       sync.Mutex
+	calls    []string
+	err      error
+	delay    time.Duration
+	active   int32
+	maxSeen  int32
+	execArgs []string
+}
+
+func (f *fakeManager) record(call string) error {
+	n := atomic.AddInt32(&f.active, 1)
+	defer atomic.AddInt32(&f.active, -1)
+	for {
+		seen := atomic.LoadInt32(&f.maxSeen)
+		if n <= seen || atomic.CompareAndSwapInt32(&f.maxSeen, seen, n) {
+			break
+		}
+	}
+
+	if f.delay > 0 {
+		time.Sleep(f.delay)
+	}
+
+	f.mu.Lock()
+	f.calls = append(f.calls, call)
+	f.mu.Unlock()
+	return f.err
+}
+
+func (f *fakeManager) Clone(ctx context.Context, repo *types.Repository) error {
+	return f.record("clone:" + repo.Name)
+}
+
+func (f *fakeManager) Update(ctx context.Context, repo *types.Repository) (*types.UpdateReport, error) {
+	if err := f.record("update:" + repo.Name); err != nil {
+		return nil, err
+	}
+	return &types.UpdateReport{Strategy: types.StrategyFFOnly, Action: "already up to date"}, nil
+}
+
+func (f *fakeManager) Status(ctx context.Context, repo *types.Repository) (*types.RepoStatus, error) {
+	if err := f.record("status:" + repo.Name); err != nil {
+		return nil, err
+	}
+	return &types.RepoStatus{Path: repo.Path, CurrentBranch: "main", IsClean: true}, nil
+}
+
+func (f *fakeManager) Execute(ctx context.Context, repo *types.Repository, command string, args ...string) (*types.Result, error) {
+	f.mu.Lock()
+	f.execArgs = append([]string{command}, args...)
+	f.mu.Unlock()
+	if err := f.record("exec:" + repo.Name); err != nil {
+		return &types.Result{Repository: repo, Error: err}, err
+	}
+	return &types.Result{Repository: repo, Output: "ok", Success: true}, nil
+}
+
+func (f *fakeManager) Exists(repo *types.Repository) bool {
+	return true
+}
+
+func TestManagedPool_DispatchesToManager(t *testing.T) {
+	m := &fakeManager{}
+	p := NewManagedPool(2, m)
+	repo := makeRepo("r1")
+
+	ops := []types.Operation{
+		makeOp(repo, OpClone),
+		makeOp(repo, OpUpdate),
+		makeOp(repo, OpStatus),
+	}
+	for result := range p.Execute(context.Background(), ops) {
+		if !result.Success {
+			t.Errorf("%s: unexpected error: %v", result.Operation, result.Error)
+		}
+		if result.Operation == OpStatus && result.Status == nil {
+			t.Error("expected status result to carry RepoStatus")
+		}
+	}
+
+	if len(m.calls) != 3 {
+		t.Errorf("expected 3 manager calls, got %v", m.calls)
+	}
+}
+
+func TestManagedPool_PropagatesErrors(t *testing.T) {
+	m := &fakeManager{err: errors.New("boom")}
+	p := NewManagedPool(1, m)
+
+	for result := range p.Execute(context.Background(), []types.Operation{makeOp(makeRepo("r1"), OpUpdate)}) {
+		if result.Success {
+			t.Error("expected failure")
+		}
+		if result.Error == nil || result.Error.Error() != "boom" {
+			t.Errorf("expected manager error, got %v", result.Error)
+		}
+	}
+}
+
+func TestManagedPool_ExecPassesArgs(t *testing.T) {
+	m := &fakeManager{}
+	p := NewManagedPool(1, m)
+
+	op := types.Operation{Repository: makeRepo("r1"), Command: OpExec, Args: []string{"git", "log", "-1"}}
+	for result := range p.Execute(context.Background(), []types.Operation{op}) {
+		if !result.Success {
+			t.Fatalf("unexpected error: %v", result.Error)
+		}
+		if result.Output != "ok" {
+			t.Errorf("expected output 'ok', got %q", result.Output)
+		}
+	}
+
+	if len(m.execArgs) != 3 || m.execArgs[0] != "git" || m.execArgs[2] != "-1" {
+		t.Errorf("unexpected exec args: %v", m.execArgs)
+	}
+}
+
+// streamingFakeManager is a fakeManager that streams its exec output
+type streamingFakeManager struct {
+	fakeManager
+}
+
+func (f *streamingFakeManager) ExecuteStream(ctx context.Context, repo *types.Repository, onLine func(line string), command string, args ...string) (*types.Result, error) {
+	for _, line := range []string{"first", "second"} {
+		onLine(line)
+	}
+	return &types.Result{Repository: repo, Output: "first\nsecond\n", ExitCode: 1, Error: errors.New("exit status 1")}, nil
+}
+
+func TestExecHandler_StreamsLines(t *testing.T) {
+	p := NewPool(1)
+	var lines []string
+	p.RegisterHandler(OpExec, ExecHandler(&streamingFakeManager{}, func(op *types.Operation, line string) {
+		lines = append(lines, op.Repository.Name+": "+line)
+	}))
+
+	op := types.Operation{Repository: makeRepo("r1"), Command: OpExec, Args: []string{"make"}}
+	for result := range p.Execute(context.Background(), []types.Operation{op}) {
+		if result.Success || result.ExitCode != 1 || result.Output != "first\nsecond\n" {
+			t.Errorf("expected the failed command's exit code and output, got %+v", result)
+		}
+	}
+	if len(lines) != 2 || lines[0] != "r1: first" || lines[1] != "r1: second" {
+		t.Errorf("unexpected streamed lines: %q", lines)
+	}
+}
+
+func TestManagedPool_ExecRequiresCommand(t *testing.T) {
+	p := NewManagedPool(1, &fakeManager{})
+
+	for result := range p.Execute(context.Background(), []types.Operation{makeOp(makeRepo("r1"), OpExec)}) {
+		if result.Success {
+			t.Error("expected failure for exec without command")
+		}
+	}
+}
+
+func TestManagedPool_RunsInParallel(t *testing.T) {
+	m := &fakeManager{delay: 50 * time.Millisecond}
+	p := NewManagedPool(4, m)
+
+	var ops []types.Operation
+	for _, name := range []string{"r1", "r2", "r3", "r4"} {
+		ops = append(ops, makeOp(makeRepo(name), OpUpdate))
+	}
+	for range p.Execute(context.Background(), ops) {
+	}
+
+	if atomic.LoadInt32(&m.maxSeen) < 2 {
+		t.Errorf("expected concurrent execution, max active was %d", m.maxSeen)
+	}
+}
+
+func TestRegisterHandler_CustomOperation(t *testing.T) {
+	p := NewPool(1)
+	p.RegisterHandler("custom", func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		result.Output = "custom:" + op.Repository.Name
+		return nil
+	})
+
+	for result := range p.Execute(context.Background(), []types.Operation{makeOp(makeRepo("r1"), "custom")}) {
+		if !result.Success || result.Output != "custom:r1" {
+			t.Errorf("unexpected result: %+v", result)
+		}
+		if result.Duration < 0 || result.StartTime.IsZero() {
+			t.Error("expected timing to be recorded")
+		}
+	}
+}
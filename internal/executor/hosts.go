@@ -0,0 +1,86 @@
+package executor
+
+import (
+	"strings"
+
+	"github.com/LederWorks/gorepos/internal/repository"
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// HostLimits caps how many network operations run against one git host at a
+// time, on top of the worker count. A limit of 0 means unlimited.
+type HostLimits struct {
+	Default int
+	Hosts   map[string]int
+}
+
+// NewHostLimits builds host limits from configuration; nil means unlimited
+func NewHostLimits(cfg *types.HostLimitConfig) HostLimits {
+	limits := HostLimits{Hosts: make(map[string]int)}
+	if cfg == nil {
+		return limits
+	}
+
+	limits.Default = cfg.Default
+	for host, limit := range cfg.Hosts {
+		limits.Hosts[strings.ToLower(host)] = limit
+	}
+	return limits
+}
+
+// limitFor returns the cap for a host, 0 meaning unlimited
+func (h HostLimits) limitFor(host string) int {
+	if limit, ok := h.Hosts[host]; ok {
+		return limit
+	}
+	return h.Default
+}
+
+// allows reports whether another operation may start against host
+func (h HostLimits) allows(host string, inFlight int) bool {
+	if host == "" {
+		return true
+	}
+	limit := h.limitFor(host)
+	return limit <= 0 || inFlight < limit
+}
+
+// SetHostLimits sets the per-host caps applied to network operations
+func (p *Pool) SetHostLimits(limits HostLimits) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.hostLimits = limits
+}
+
+// GetHostLimits returns the per-host caps applied to network operations
+func (p *Pool) GetHostLimits() HostLimits {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.hostLimits
+}
+
+// RegisterNetworkHandler registers a handler whose operations talk to the
+// repository's remote and are therefore subject to host limits
+func (p *Pool) RegisterNetworkHandler(command string, handler Handler) {
+	p.RegisterHandler(command, handler)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.network == nil {
+		p.network = make(map[string]bool)
+	}
+	p.network[command] = true
+}
+
+// hostFor returns the remote host an operation is throttled against, or an
+// empty string for local operations
+func (p *Pool) hostFor(op *types.Operation) string {
+	p.mu.RLock()
+	network := p.network[op.Command]
+	p.mu.RUnlock()
+
+	if !network || op.Repository == nil {
+		return ""
+	}
+	return repository.HostFromURL(op.Repository.URL)
+}
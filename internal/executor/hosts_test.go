@@ -0,0 +1,107 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// hostTracker records the peak number of concurrent operations per host
+type hostTracker struct {
+	mu      sync.Mutex
+	active  map[string]int
+	maxSeen map[string]int
+	total   int
+	peak    int
+}
+
+func newHostTracker() *hostTracker {
+	return &hostTracker{active: make(map[string]int), maxSeen: make(map[string]int)}
+}
+
+func (h *hostTracker) handler(ctx context.Context, op *types.Operation, result *types.Result) error {
+	host := op.Repository.Path // tests store the host in the path for lookup
+
+	h.mu.Lock()
+	h.active[host]++
+	h.total++
+	if h.active[host] > h.maxSeen[host] {
+		h.maxSeen[host] = h.active[host]
+	}
+	if h.total > h.peak {
+		h.peak = h.total
+	}
+	h.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	h.mu.Lock()
+	h.active[host]--
+	h.total--
+	h.mu.Unlock()
+	return nil
+}
+
+func hostOps(command string, count int, host, url string) []types.Operation {
+	var ops []types.Operation
+	for i := 0; i < count; i++ {
+		repo := &types.Repository{
+			Name: fmt.Sprintf("%s-%d", host, i),
+			Path: host,
+			URL:  fmt.Sprintf(url, i),
+		}
+		ops = append(ops, makeOp(repo, command))
+	}
+	return ops
+}
+
+func TestNewHostLimits(t *testing.T) {
+	limits := NewHostLimits(&types.HostLimitConfig{Default: 2, Hosts: map[string]int{"GitHub.com": 5}})
+	if limits.limitFor("github.com") != 5 {
+		t.Errorf("expected github.com limit 5, got %d", limits.limitFor("github.com"))
+	}
+	if limits.limitFor("gitlab.com") != 2 {
+		t.Errorf("expected default limit 2, got %d", limits.limitFor("gitlab.com"))
+	}
+	if !NewHostLimits(nil).allows("github.com", 1000) {
+		t.Error("expected nil config to be unlimited")
+	}
+}
+
+func TestExecute_EnforcesHostLimits(t *testing.T) {
+	tracker := newHostTracker()
+	p := NewPool(10)
+	p.RegisterNetworkHandler("fetch", tracker.handler)
+	p.SetHostLimits(NewHostLimits(&types.HostLimitConfig{Default: 2, Hosts: map[string]int{"github.com": 3}}))
+
+	ops := hostOps("fetch", 6, "github", "https://github.com/org/repo%d.git")
+	ops = append(ops, hostOps("fetch", 6, "gitlab", "git@gitlab.com:org/repo%d.git")...)
+	runOps(p, ops)
+
+	if tracker.maxSeen["github"] != 3 {
+		t.Errorf("expected github.com capped at 3, peaked at %d", tracker.maxSeen["github"])
+	}
+	if tracker.maxSeen["gitlab"] != 2 {
+		t.Errorf("expected gitlab.com capped at the default 2, peaked at %d", tracker.maxSeen["gitlab"])
+	}
+	if tracker.peak < 4 {
+		t.Errorf("expected different hosts to run side by side, peak was %d", tracker.peak)
+	}
+}
+
+func TestExecute_LocalOperationsIgnoreHostLimits(t *testing.T) {
+	tracker := newHostTracker()
+	p := NewPool(6)
+	p.RegisterHandler("local", tracker.handler)
+	p.SetHostLimits(NewHostLimits(&types.HostLimitConfig{Default: 1}))
+
+	runOps(p, hostOps("local", 6, "github", "https://github.com/org/repo%d.git"))
+
+	if tracker.maxSeen["github"] < 2 {
+		t.Errorf("expected local operations to run unthrottled, peaked at %d", tracker.maxSeen["github"])
+	}
+}
@@ -0,0 +1,160 @@
+package executor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/LederWorks/gorepos/internal/state"
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// journalPending marks an operation that was queued but has not finished
+const journalPending types.ResultState = "pending"
+
+// Journal records operation outcomes on disk as they finish, so an
+// interrupted run can be resumed with only the unfinished operations
+type Journal struct {
+	mu   sync.Mutex
+	path string
+	err  error
+	data journalData
+}
+
+// journalData is the on-disk form of a journal
+type journalData struct {
+	Operation string                  `json:"operation"`
+	StartedAt time.Time               `json:"startedAt"`
+	UpdatedAt time.Time               `json:"updatedAt"`
+	Entries   map[string]JournalEntry `json:"entries"`
+}
+
+// JournalEntry is the last known outcome of one repository's operation
+type JournalEntry struct {
+	State    types.ResultState   `json:"state"`
+	Category types.ErrorCategory `json:"category,omitempty"`
+	Error    string              `json:"error,omitempty"`
+	Attempts int                 `json:"attempts,omitempty"`
+	Updated  time.Time           `json:"updated"`
+}
+
+// NewJournal creates an empty journal for an operation, written to path
+func NewJournal(path, operation string) *Journal {
+	return &Journal{
+		path: path,
+		data: journalData{
+			Operation: operation,
+			StartedAt: time.Now(),
+			Entries:   make(map[string]JournalEntry),
+		},
+	}
+}
+
+// LoadJournal reads a journal written by a previous run. A missing journal
+// returns an error matching os.ErrNotExist.
+func LoadJournal(path string) (*Journal, error) {
+	j := &Journal{path: path}
+	if err := state.ReadJSON(path, &j.data); err != nil {
+		return nil, err
+	}
+	if j.data.Entries == nil {
+		j.data.Entries = make(map[string]JournalEntry)
+	}
+	return j, nil
+}
+
+// Path returns where the journal is written
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Entry returns the recorded outcome for a repository
+func (j *Journal) Entry(name string) (JournalEntry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entry, ok := j.data.Entries[name]
+	return entry, ok
+}
+
+// Pending returns the operations that did not succeed in the journaled run,
+// including operations the journal has no record of
+func (j *Journal) Pending(operations []types.Operation) []types.Operation {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var pending []types.Operation
+	for _, op := range operations {
+		if op.Repository == nil {
+			continue
+		}
+		if entry, ok := j.data.Entries[op.Repository.Name]; ok && entry.State == types.ResultSucceeded {
+			continue
+		}
+		pending = append(pending, op)
+	}
+	return pending
+}
+
+// begin marks a batch of operations as pending and saves the journal
+func (j *Journal) begin(operations []types.Operation) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	for _, op := range operations {
+		if op.Repository != nil {
+			j.data.Entries[op.Repository.Name] = JournalEntry{State: journalPending, Updated: now}
+		}
+	}
+	j.saveLocked()
+}
+
+// record stores a finished operation's outcome and saves the journal
+func (j *Journal) record(result types.Result) {
+	if result.Repository == nil {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry := JournalEntry{
+		State:    result.State,
+		Category: result.Category,
+		Attempts: result.Attempts,
+		Updated:  time.Now(),
+	}
+	if result.Error != nil {
+		entry.Error = result.Error.Error()
+	}
+	j.data.Entries[result.Repository.Name] = entry
+	j.saveLocked()
+}
+
+// saveLocked writes the journal to disk, remembering the first write error
+func (j *Journal) saveLocked() {
+	j.data.UpdatedAt = time.Now()
+	if err := state.WriteJSON(j.path, &j.data); err != nil && j.err == nil {
+		j.err = err
+	}
+}
+
+// Err returns the first error encountered while writing the journal
+func (j *Journal) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+// SetJournal makes the pool record every batch's outcomes in j; nil disables it
+func (p *Pool) SetJournal(j *Journal) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.journal = j
+}
+
+// getJournal returns the pool's journal, if any
+func (p *Pool) getJournal() *Journal {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.journal
+}
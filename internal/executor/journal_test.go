@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+func TestJournal_RecordsOutcomes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	p := NewPool(2)
+	p.RegisterHandler("build", newOrderRecorder("bad").handler)
+	p.SetJournal(NewJournal(path, "build"))
+
+	runOps(p, []types.Operation{
+		makeOp(makeRepo("good"), "build"),
+		makeOp(makeRepo("bad"), "build"),
+	})
+
+	journal, err := LoadJournal(path)
+	if err != nil {
+		t.Fatalf("failed to load journal: %v", err)
+	}
+	if entry, _ := journal.Entry("good"); entry.State != types.ResultSucceeded {
+		t.Errorf("expected good to be recorded as succeeded, got %q", entry.State)
+	}
+	entry, _ := journal.Entry("bad")
+	if entry.State != types.ResultFailed || entry.Error == "" {
+		t.Errorf("expected bad to be recorded as failed with an error, got %+v", entry)
+	}
+}
+
+func TestJournal_PendingSkipsSucceeded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	p := NewPool(1)
+	p.RegisterHandler("build", newOrderRecorder("bad").handler)
+	p.SetJournal(NewJournal(path, "build"))
+
+	ops := []types.Operation{
+		makeOp(makeRepo("good"), "build"),
+		makeOp(makeRepo("bad"), "build"),
+	}
+	runOps(p, ops)
+
+	journal, err := LoadJournal(path)
+	if err != nil {
+		t.Fatalf("failed to load journal: %v", err)
+	}
+	ops = append(ops, makeOp(makeRepo("new"), "build"))
+	pending := journal.Pending(ops)
+
+	if len(pending) != 2 || pending[0].Repository.Name != "bad" || pending[1].Repository.Name != "new" {
+		t.Errorf("expected bad and new to be pending, got %v", pending)
+	}
+}
+
+func TestJournal_RecordsCancelledOperations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	p := NewPool(1)
+	p.RegisterHandler("build", newOrderRecorder().handler)
+	p.SetJournal(NewJournal(path, "build"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range p.Execute(ctx, []types.Operation{makeOp(makeRepo("r1"), "build")}) {
+	}
+
+	journal, err := LoadJournal(path)
+	if err != nil {
+		t.Fatalf("failed to load journal: %v", err)
+	}
+	if entry, _ := journal.Entry("r1"); entry.State != types.ResultCancelled {
+		t.Errorf("expected cancelled entry, got %q", entry.State)
+	}
+	if len(journal.Pending([]types.Operation{makeOp(makeRepo("r1"), "build")})) != 1 {
+		t.Error("expected cancelled operation to be pending")
+	}
+}
+
+func TestLoadJournal_Missing(t *testing.T) {
+	_, err := LoadJournal(filepath.Join(t.TempDir(), "missing.json"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+}
+
+func TestJournal_ReportsWriteErrors(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "file")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	journal := NewJournal(filepath.Join(blocker, "journal.json"), "build")
+	journal.record(types.Result{Repository: makeRepo("r1"), State: types.ResultSucceeded})
+
+	if journal.Err() == nil {
+		t.Error("expected write error to be reported")
+	}
+}
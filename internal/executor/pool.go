@@ -4,210 +4,500 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/LederWorks/gorepos/pkg/types"
 )
 
-// Pool implements the Executor interface with a worker pool
+// Pool implements the Executor interface with a worker pool. The worker
+// count can be changed while batches are running.
 type Pool struct {
 	workerCount int
-	workers     []*worker
 	mu          sync.RWMutex
 	started     bool
+	batches     map[chan struct{}]struct{} // resize signals of running batches
+	running     sync.WaitGroup             // running batches
+	active      int32                      // live worker goroutines across batches
+	handlers    map[string]Handler
+	network     map[string]bool // operations subject to host limits
+	ordered     map[string]bool // operations that wait for their dependencies
+	hostLimits  HostLimits
+	timeout     time.Duration
+	maxFailures int // failures after which a batch stops, 0 for no limit
+	retry       RetryPolicy
+	journal     *Journal
+	subMu       sync.Mutex
+	subscribers []chan Event
 }
 
-// worker represents a single worker in the pool
-type worker struct {
-	id      int
-	jobs    chan types.Operation
-	results chan types.Result
-	done    chan bool
-	wg      *sync.WaitGroup
-}
+// retireWorker is sent on a batch's job channel to stop one idle worker
+const retireWorker = -1
 
-// NewPool creates a new executor pool
+// NewPool creates a new executor pool. The worker count is clamped to 1..100.
 func NewPool(workerCount int) *Pool {
 	return &Pool{
-		workerCount: workerCount,
+		workerCount: clampWorkers(workerCount),
+		batches:     make(map[chan struct{}]struct{}),
+		handlers:    make(map[string]Handler),
+		network:     make(map[string]bool),
+		ordered:     make(map[string]bool),
+		retry:       NoRetry(),
 	}
 }
 
-// Execute processes operations in parallel using the worker pool
+// Execute processes operations in parallel using the worker pool. Ready
+// operations start in order of descending repository priority. Operations
+// named in OrderByDependencies start only after the operations for their
+// repository's dependsOn entries in the same batch have succeeded; dependents
+// of a failed operation are skipped.
 func (p *Pool) Execute(ctx context.Context, operations []types.Operation) <-chan types.Result {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	// Initialize workers if not started
-	if !p.started {
-		p.start()
-	}
+	p.started = true
 
-	results := make(chan types.Result, len(operations))
+	resize := make(chan struct{}, 1)
+	p.batches[resize] = struct{}{}
+	p.running.Add(1)
 
+	results := make(chan types.Result, len(operations))
 	go func() {
-		defer close(results)
+		defer p.running.Done()
+		defer p.endBatch(resize)
+		p.dispatch(ctx, operations, resize, results)
+	}()
 
-		var wg sync.WaitGroup
-		jobChan := make(chan types.Operation, len(operations))
+	return results
+}
 
-		// Start workers
-		for i := 0; i < p.workerCount; i++ {
-			wg.Add(1)
-			go p.worker(ctx, i, jobChan, results, &wg)
+// endBatch stops delivering resize signals to a finished batch
+func (p *Pool) endBatch(resize chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.batches, resize)
+}
+
+// completion pairs a finished operation with its index in the batch
+type completion struct {
+	index  int
+	result types.Result
+}
+
+// dispatch feeds ready operations to workers and releases dependents as
+// results come back. It closes results once every operation is accounted for.
+// Network operations are held back while their host is at its limit.
+// A signal on resize makes it grow or shrink its workers to the pool's
+// current worker count; surplus workers retire once they are idle.
+func (p *Pool) dispatch(ctx context.Context, operations []types.Operation, resize <-chan struct{}, results chan<- types.Result) {
+	defer close(results)
+
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	failureLimit := p.GetFailureLimit()
+	var summary Summary
+
+	plan := newDependencyPlan(operations, p.isOrdered)
+	pending := len(operations)
+	total := len(operations)
+
+	journal := p.getJournal()
+	if journal != nil {
+		journal.begin(operations)
+	}
+	record := func(result types.Result) {
+		if journal != nil {
+			journal.record(result)
 		}
+	}
 
-		// Send operations to workers
-		go func() {
-			defer close(jobChan)
-			for _, op := range operations {
-				select {
-				case jobChan <- op:
-				case <-ctx.Done():
-					return
-				}
-			}
-		}()
+	// finish reports a result for an operation that never reached a worker
+	finish := func(result types.Result) {
+		record(result)
+		p.emit(Event{Type: EventFinished, Worker: -1, Repository: result.Repository, Operation: result.Operation, Result: &result, Total: total})
+		results <- result
+	}
+
+	for i := range operations {
+		p.emit(Event{Type: EventQueued, Worker: -1, Repository: operations[i].Repository, Operation: operations[i].Command, Total: total})
+	}
 
-		// Wait for all workers to complete
+	// Operations in a dependency cycle can never start
+	for _, i := range plan.blocked() {
+		plan.settle(i)
+		finish(cycleResult(&operations[i]))
+		pending--
+	}
+
+	ready := plan.ready()
+	plan.prioritize(ready)
+
+	limits := p.GetHostLimits()
+	hosts := make([]string, len(operations))
+	for i := range operations {
+		hosts[i] = p.hostFor(&operations[i])
+	}
+	inFlight := make(map[string]int)
+
+	jobs := make(chan int)
+	done := make(chan completion, len(operations))
+	var wg sync.WaitGroup
+	live, nextID := 0, 0
+	target := clampWorkers(p.GetWorkerCount())
+	spawn := func() {
+		for live < target {
+			wg.Add(1)
+			atomic.AddInt32(&p.active, 1)
+			go p.worker(ctx, nextID, operations, jobs, done, &wg)
+			live++
+			nextID++
+		}
+	}
+	spawn()
+	defer func() {
+		close(jobs)
 		wg.Wait()
 	}()
 
-	return results
-}
+	// complete settles a finished operation, releasing or skipping its dependents
+	complete := func(c completion) {
+		pending--
+		if hosts[c.index] != "" {
+			inFlight[hosts[c.index]]--
+		}
+		record(c.result)
+		results <- c.result
 
-// worker processes operations from the job channel
-func (p *Pool) worker(ctx context.Context, id int, jobs <-chan types.Operation, results chan<- types.Result, wg *sync.WaitGroup) {
-	defer wg.Done()
+		// Stop before handing out another job once too many have failed
+		summary.Add(c.result)
+		if summary.ReachedFailureLimit(failureLimit) {
+			stop()
+		}
 
-	for {
+		if c.result.Success {
+			ready = append(ready, plan.succeed(c.index)...)
+			plan.prioritize(ready)
+			return
+		}
+		for _, i := range plan.fail(c.index) {
+			finish(skippedResult(&operations[i], &operations[c.index]))
+			pending--
+		}
+	}
+
+	ctxDone := ctx.Done()
+	started := make([]bool, len(operations))
+	for pending > 0 {
+		// Settle finished work first so released high-priority operations
+		// are considered before the next job is handed out
 		select {
-		case job, ok := <-jobs:
-			if !ok {
-				return
+		case c := <-done:
+			complete(c)
+			continue
+		default:
+		}
+
+		var jobChan chan<- int
+		next, pick := retireWorker, -1
+		if live > target {
+			jobChan = jobs
+		} else if ctx.Err() == nil {
+			for pos, i := range ready {
+				if limits.allows(hosts[i], inFlight[hosts[i]]) {
+					jobChan, next, pick = jobs, i, pos
+					break
+				}
 			}
+		}
 
-			result := p.executeOperation(ctx, &job)
+		select {
+		case jobChan <- next:
+			if next == retireWorker {
+				live--
+				continue
+			}
+			ready = append(ready[:pick], ready[pick+1:]...)
+			started[next] = true
+			if hosts[next] != "" {
+				inFlight[hosts[next]]++
+			}
 
-			select {
-			case results <- *result:
-			case <-ctx.Done():
-				return
+		case <-resize:
+			target = clampWorkers(p.GetWorkerCount())
+			spawn()
+
+		case c := <-done:
+			complete(c)
+
+		case <-ctxDone:
+			// Stop dispatching; report everything that has not started
+			ctxDone = nil
+			ready = nil
+			for _, i := range plan.unsettled() {
+				if !started[i] {
+					plan.settle(i)
+					finish(cancelledResult(&operations[i], ctx.Err()))
+					pending--
+				}
 			}
+		}
+	}
+}
+
+// worker runs operations received on jobs until the channel is closed or it
+// is told to retire
+func (p *Pool) worker(ctx context.Context, id int, operations []types.Operation, jobs <-chan int, done chan<- completion, wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer atomic.AddInt32(&p.active, -1)
 
-		case <-ctx.Done():
+	total := len(operations)
+	for i := range jobs {
+		if i == retireWorker {
 			return
 		}
+		op := &operations[i]
+		p.emit(Event{Type: EventStarted, Worker: id, Repository: op.Repository, Operation: op.Command, Total: total})
+
+		opCtx := ctx
+		if p.hasSubscribers() {
+			opCtx = types.WithProgress(ctx, func(message string) {
+				p.emit(Event{Type: EventProgress, Worker: id, Repository: op.Repository, Operation: op.Command, Message: message, Total: total})
+			})
+		}
+
+		result := p.executeOperation(opCtx, op)
+		p.emit(Event{Type: EventFinished, Worker: id, Repository: op.Repository, Operation: op.Command, Result: result, Total: total})
+		done <- completion{index: i, result: *result}
 	}
 }
 
-// executeOperation executes a single operation
+// executeOperation dispatches a single operation to its registered handler,
+// retrying retryable failures according to the pool's retry policy
 func (p *Pool) executeOperation(ctx context.Context, op *types.Operation) *types.Result {
-	// This is a simplified execution - in practice, this would delegate to
-	// the appropriate service (repository manager, etc.)
-
+	startTime := time.Now()
 	result := &types.Result{
 		Repository: op.Repository,
 		Operation:  op.Command,
+		StartTime:  startTime,
 	}
 
 	// Check context cancellation
 	if ctx.Err() != nil {
 		result.Error = ctx.Err()
+		result.State = types.ResultCancelled
 		result.Success = false
 		return result
 	}
 
-	// For now, we'll just simulate the operation
-	// In a real implementation, this would call the appropriate manager
-	switch op.Command {
-	case "clone":
-		result.Output = fmt.Sprintf("Would clone %s to %s", op.Repository.URL, op.Repository.Path)
-		result.Success = true
-	case "update":
-		result.Output = fmt.Sprintf("Would update repository at %s", op.Repository.Path)
-		result.Success = true
-	case "status":
-		result.Output = fmt.Sprintf("Would check status of repository at %s", op.Repository.Path)
-		result.Success = true
-	default:
+	handler, ok := p.getHandler(op.Command)
+	if !ok {
 		result.Error = fmt.Errorf("unknown operation: %s", op.Command)
+		result.State = types.ResultFailed
+		result.Category = types.ErrorUnknown
 		result.Success = false
+		return result
 	}
 
+	policy := p.GetRetryPolicy()
+	for attempt := 1; ; attempt++ {
+		result = p.runAttempt(ctx, op, handler)
+		result.Attempts = attempt
+
+		if result.Success || !policy.ShouldRetry(result.Category, attempt) {
+			break
+		}
+		if !sleepContext(ctx, policy.Backoff(attempt)) {
+			break
+		}
+	}
+
+	result.StartTime = startTime
+	result.Duration = time.Since(startTime)
+
 	return result
 }
 
-// SetWorkerCount updates the number of workers
-func (p *Pool) SetWorkerCount(count int) {
+// runAttempt runs the handler once under the operation's deadline
+func (p *Pool) runAttempt(ctx context.Context, op *types.Operation, handler Handler) *types.Result {
+	result := &types.Result{
+		Repository: op.Repository,
+		Operation:  op.Command,
+		StartTime:  time.Now(),
+	}
+
+	timeout := p.timeoutFor(op)
+	opCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		opCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// Run the handler on its own copy of the result so a handler that ignores
+	// its context cannot hold the worker past the deadline
+	handlerResult := *result
+	done := make(chan error, 1)
+	go func() {
+		done <- handler(opCtx, op, &handlerResult)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+		*result = handlerResult
+	case <-opCtx.Done():
+		err = opCtx.Err()
+	}
+
+	switch {
+	case err == nil:
+		result.State = types.ResultSucceeded
+		result.Success = true
+	case ctx.Err() != nil:
+		result.State = types.ResultCancelled
+		result.Error = err
+	case opCtx.Err() == context.DeadlineExceeded:
+		result.State = types.ResultTimedOut
+		result.Category = types.ErrorTimeout
+		result.Error = fmt.Errorf("%s timed out after %s: %w", op.Command, timeout, err)
+	default:
+		result.State = types.ResultFailed
+		result.Category = types.CategoryOf(err)
+		result.Error = err
+		if result.Category.IsRefusal() {
+			result.State = types.ResultRefused
+		}
+	}
+	result.Duration = time.Since(result.StartTime)
+
+	return result
+}
+
+// timeoutFor returns the deadline for an operation, preferring the repository override
+func (p *Pool) timeoutFor(op *types.Operation) time.Duration {
+	if op.Repository != nil && op.Repository.Timeout > 0 {
+		return op.Repository.Timeout
+	}
+	return p.GetTimeout()
+}
+
+// SetTimeout sets the default per-operation timeout (0 disables it)
+func (p *Pool) SetTimeout(timeout time.Duration) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	if count < 1 {
-		count = 1
+	if timeout < 0 {
+		timeout = 0
 	}
-	if count > 100 {
-		count = 100
+	p.timeout = timeout
+}
+
+// SetFailureLimit makes a batch stop after this many operations failed:
+// running operations are cancelled and no further ones start. 0 disables it.
+func (p *Pool) SetFailureLimit(limit int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if limit < 0 {
+		limit = 0
 	}
+	p.maxFailures = limit
+}
+
+// GetFailureLimit returns the number of failures that stop a batch
+func (p *Pool) GetFailureLimit() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.maxFailures
+}
 
-	p.workerCount = count
+// SetRetryPolicy sets the policy used to retry failed operations
+func (p *Pool) SetRetryPolicy(policy RetryPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	// If already started, we'd need to restart with new worker count
-	// For simplicity, we'll require stopping and restarting
-	if p.started {
-		// In a production implementation, you might want to gracefully
-		// resize the pool without stopping
-		fmt.Printf("Warning: Worker count changed to %d. Restart required for changes to take effect.\n", count)
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
 	}
+	p.retry = policy
 }
 
-// Shutdown gracefully shuts down the executor pool
-func (p *Pool) Shutdown(ctx context.Context) error {
+// GetRetryPolicy returns the policy used to retry failed operations
+func (p *Pool) GetRetryPolicy() RetryPolicy {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.retry
+}
+
+// GetTimeout returns the default per-operation timeout
+func (p *Pool) GetTimeout() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.timeout
+}
+
+// SetWorkerCount updates the number of workers. Running batches are resized
+// immediately: new workers start right away and surplus workers stop after
+// finishing their current operation.
+func (p *Pool) SetWorkerCount(count int) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	if !p.started {
-		return nil
+	p.workerCount = clampWorkers(count)
+
+	for resize := range p.batches {
+		select {
+		case resize <- struct{}{}:
+		default:
+			// A resize is already pending; the batch reads the latest count
+		}
 	}
+}
 
-	// Signal all workers to stop
-	for _, worker := range p.workers {
-		close(worker.done)
+// clampWorkers limits a worker count to 1..100 so a batch always has a
+// worker to drain its queue
+func clampWorkers(count int) int {
+	if count < 1 {
+		return 1
 	}
+	if count > 100 {
+		return 100
+	}
+	return count
+}
 
-	// Wait for workers to finish with timeout
-	done := make(chan bool)
+// Shutdown waits for running batches to finish. Cancel their contexts first
+// to stop them early.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.RLock()
+	started := p.started
+	p.mu.RUnlock()
+
+	if !started {
+		return nil
+	}
+
+	done := make(chan struct{})
 	go func() {
-		for _, worker := range p.workers {
-			worker.wg.Wait()
-		}
-		done <- true
+		p.running.Wait()
+		close(done)
 	}()
 
 	select {
 	case <-done:
+		p.mu.Lock()
 		p.started = false
-		p.workers = nil
+		p.mu.Unlock()
 		return nil
 	case <-ctx.Done():
 		return ctx.Err()
 	}
 }
 
-// start initializes the workers
-func (p *Pool) start() {
-	p.workers = make([]*worker, p.workerCount)
-
-	for i := 0; i < p.workerCount; i++ {
-		p.workers[i] = &worker{
-			id:      i,
-			jobs:    make(chan types.Operation, 10),
-			results: make(chan types.Result, 10),
-			done:    make(chan bool),
-			wg:      &sync.WaitGroup{},
-		}
-	}
-
-	p.started = true
+// ActiveWorkers returns the number of worker goroutines currently running
+// across all batches
+func (p *Pool) ActiveWorkers() int {
+	return int(atomic.LoadInt32(&p.active))
 }
 
 // GetWorkerCount returns the current worker count
@@ -2,6 +2,8 @@ package executor
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -32,6 +34,29 @@ func TestNewPool_InitialState(t *testing.T) {
 	}
 }
 
+func TestNewPool_ClampsWorkerCount(t *testing.T) {
+	if got := NewPool(0).GetWorkerCount(); got != 1 {
+		t.Errorf("expected clamped min of 1, got %d", got)
+	}
+	if got := NewPool(200).GetWorkerCount(); got != 100 {
+		t.Errorf("expected clamped max of 100, got %d", got)
+	}
+}
+
+func TestExecute_ZeroWorkersStillRuns(t *testing.T) {
+	p := NewManagedPool(0, &fakeManager{})
+	results := p.Execute(context.Background(), []types.Operation{makeOp(makeRepo("r1"), "status")})
+
+	select {
+	case result := <-results:
+		if !result.Success {
+			t.Errorf("expected success, got %v", result.Error)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a pool created with 0 workers")
+	}
+}
+
 // --- SetWorkerCount ---
 
 func TestSetWorkerCount_Normal(t *testing.T) {
@@ -107,7 +132,7 @@ func TestExecute_ProcessesAllOperations(t *testing.T) {
 }
 
 func TestExecute_KnownCommands_AreSuccessful(t *testing.T) {
-	p := NewPool(1)
+	p := NewManagedPool(1, &fakeManager{})
 	ctx := context.Background()
 	repo := makeRepo("r1")
 
@@ -274,3 +299,282 @@ func TestExecute_MultipleCallsSerially(t *testing.T) {
 		}
 	}
 }
+
+// --- Timeouts ---
+
+func TestExecute_TimeoutProducesTimedOutResult(t *testing.T) {
+	p := NewPool(1)
+	p.SetTimeout(20 * time.Millisecond)
+	p.RegisterHandler("slow", func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	for result := range p.Execute(context.Background(), []types.Operation{makeOp(makeRepo("r1"), "slow")}) {
+		if result.State != types.ResultTimedOut {
+			t.Errorf("expected timed out state, got %q", result.State)
+		}
+		if result.Success || result.Error == nil {
+			t.Error("expected timed out result to be a failure with an error")
+		}
+	}
+}
+
+func TestExecute_TimeoutDoesNotWaitForStuckHandler(t *testing.T) {
+	p := NewPool(1)
+	p.SetTimeout(20 * time.Millisecond)
+	release := make(chan struct{})
+	defer close(release)
+	p.RegisterHandler("stuck", func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		<-release // ignores its context
+		return nil
+	})
+
+	start := time.Now()
+	for result := range p.Execute(context.Background(), []types.Operation{makeOp(makeRepo("r1"), "stuck")}) {
+		if result.State != types.ResultTimedOut {
+			t.Errorf("expected timed out state, got %q", result.State)
+		}
+	}
+	if time.Since(start) > time.Second {
+		t.Error("pool waited for a handler that ignored its deadline")
+	}
+}
+
+func TestExecute_RepositoryTimeoutOverridesDefault(t *testing.T) {
+	p := NewPool(2)
+	p.SetTimeout(time.Hour)
+	p.RegisterHandler("slow", func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+			return nil
+		}
+	})
+
+	fast := makeRepo("fast")
+	fast.Timeout = 10 * time.Millisecond
+	ops := []types.Operation{makeOp(fast, "slow"), makeOp(makeRepo("default"), "slow")}
+
+	states := make(map[string]types.ResultState)
+	for result := range p.Execute(context.Background(), ops) {
+		states[result.Repository.Name] = result.State
+	}
+
+	if states["fast"] != types.ResultTimedOut {
+		t.Errorf("expected repository override to time out, got %q", states["fast"])
+	}
+	if states["default"] != types.ResultSucceeded {
+		t.Errorf("expected default timeout to allow completion, got %q", states["default"])
+	}
+}
+
+func TestSetTimeout_ClampsNegative(t *testing.T) {
+	p := NewPool(1)
+	p.SetTimeout(-time.Second)
+	if p.GetTimeout() != 0 {
+		t.Errorf("expected negative timeout to disable deadline, got %s", p.GetTimeout())
+	}
+}
+
+// --- Failure limit ---
+
+// runWithFailures runs the operations one at a time under the failure limit,
+// failing those whose repository is named in fail, and returns the handler
+// calls and the summary
+func runWithFailures(t *testing.T, limit int, names []string, fail ...string) ([]string, Summary) {
+	t.Helper()
+	failing := make(map[string]bool)
+	for _, name := range fail {
+		failing[name] = true
+	}
+
+	p := NewPool(1)
+	p.SetFailureLimit(limit)
+	var calls []string
+	p.RegisterHandler("work", func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		calls = append(calls, op.Repository.Name)
+		if failing[op.Repository.Name] {
+			return fmt.Errorf("%s failed", op.Repository.Name)
+		}
+		return nil
+	})
+
+	var ops []types.Operation
+	for _, name := range names {
+		ops = append(ops, makeOp(makeRepo(name), "work"))
+	}
+	var summary Summary
+	for result := range p.Execute(context.Background(), ops) {
+		summary.Add(result)
+	}
+	return calls, summary
+}
+
+func TestExecute_FailureLimitStopsNewOperations(t *testing.T) {
+	calls, summary := runWithFailures(t, 2, []string{"r1", "r2", "r3", "r4", "r5"}, "r1", "r2", "r3", "r4", "r5")
+
+	if len(calls) != 2 {
+		t.Errorf("expected no operation to start after the second failure, got calls %v", calls)
+	}
+	if summary.Failed != 2 || summary.Cancelled != 3 {
+		t.Errorf("expected 2 failed and 3 cancelled, got %+v", summary)
+	}
+}
+
+func TestExecute_FailureLimitBoundary(t *testing.T) {
+	names := []string{"r1", "r2", "r3", "r4", "r5"}
+
+	// Two failures stay below a limit of three
+	calls, summary := runWithFailures(t, 3, names, "r1", "r3")
+	if len(calls) != 5 || summary.Cancelled != 0 {
+		t.Errorf("expected every operation to run below the limit, got calls %v and %+v", calls, summary)
+	}
+
+	// The second failure reaches a limit of two
+	calls, summary = runWithFailures(t, 2, names, "r1", "r3")
+	if len(calls) != 3 || summary.Succeeded != 1 || summary.Failed != 2 || summary.Cancelled != 2 {
+		t.Errorf("expected the run to stop at the second failure, got calls %v and %+v", calls, summary)
+	}
+
+	// No limit runs everything
+	calls, _ = runWithFailures(t, 0, names, names...)
+	if len(calls) != 5 {
+		t.Errorf("expected every operation to run without a limit, got calls %v", calls)
+	}
+}
+
+func TestExecute_FailureLimitCancelsRunningOperations(t *testing.T) {
+	p := NewPool(2)
+	p.SetFailureLimit(1)
+	p.RegisterHandler("work", func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		if op.Repository.Name == "fails" {
+			time.Sleep(10 * time.Millisecond)
+			return fmt.Errorf("failed")
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ops := []types.Operation{makeOp(makeRepo("fails"), "work"), makeOp(makeRepo("slow"), "work")}
+	results := make(map[string]types.Result)
+	for result := range p.Execute(context.Background(), ops) {
+		results[result.Repository.Name] = result
+	}
+	if results["slow"].State != types.ResultCancelled {
+		t.Errorf("expected the running operation to be cancelled, got %q", results["slow"].State)
+	}
+}
+
+// --- Resizing ---
+
+// waitFor polls cond until it holds or the deadline passes
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSetWorkerCount_GrowsRunningBatch(t *testing.T) {
+	p := NewPool(1)
+	release := make(chan struct{})
+	var running int32
+	p.RegisterHandler("block", func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		atomic.AddInt32(&running, 1)
+		<-release
+		return nil
+	})
+
+	ops := []types.Operation{
+		makeOp(makeRepo("r1"), "block"),
+		makeOp(makeRepo("r2"), "block"),
+		makeOp(makeRepo("r3"), "block"),
+	}
+	results := p.Execute(context.Background(), ops)
+
+	waitFor(t, func() bool { return atomic.LoadInt32(&running) == 1 })
+	p.SetWorkerCount(3)
+	waitFor(t, func() bool { return atomic.LoadInt32(&running) == 3 })
+
+	close(release)
+	count := 0
+	for range results {
+		count++
+	}
+	if count != 3 {
+		t.Errorf("expected 3 results, got %d", count)
+	}
+}
+
+func TestSetWorkerCount_ShrinksRunningBatch(t *testing.T) {
+	p := NewPool(4)
+	release := make(chan struct{})
+	var running, maxAfterShrink int32
+	var shrunk atomic.Bool
+	p.RegisterHandler("block", func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		if shrunk.Load() && n > atomic.LoadInt32(&maxAfterShrink) {
+			atomic.StoreInt32(&maxAfterShrink, n)
+		}
+		<-release
+		return nil
+	})
+
+	var ops []types.Operation
+	for i := 0; i < 8; i++ {
+		ops = append(ops, makeOp(makeRepo(fmt.Sprintf("r%d", i)), "block"))
+	}
+	results := p.Execute(context.Background(), ops)
+
+	waitFor(t, func() bool { return atomic.LoadInt32(&running) == 4 })
+	p.SetWorkerCount(1)
+	shrunk.Store(true)
+	time.Sleep(20 * time.Millisecond) // let the batch pick up the new count
+	close(release)
+
+	count := 0
+	for range results {
+		count++
+	}
+	if count != 8 {
+		t.Errorf("expected 8 results, got %d", count)
+	}
+	if maxAfterShrink > 1 {
+		t.Errorf("expected at most 1 concurrent operation after shrinking, saw %d", maxAfterShrink)
+	}
+}
+
+func TestSetWorkerCount_RetiresIdleWorkers(t *testing.T) {
+	p := NewPool(4)
+	release := make(chan struct{})
+	p.RegisterHandler("block", func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		<-release
+		return nil
+	})
+
+	results := p.Execute(context.Background(), []types.Operation{makeOp(makeRepo("r1"), "block")})
+
+	waitFor(t, func() bool { return p.ActiveWorkers() == 4 })
+	p.SetWorkerCount(2)
+	waitFor(t, func() bool { return p.ActiveWorkers() == 2 })
+
+	close(release)
+	for range results {
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if p.ActiveWorkers() != 0 {
+		t.Errorf("expected no active workers after shutdown, got %d", p.ActiveWorkers())
+	}
+	if p.IsStarted() {
+		t.Error("pool should not be started after shutdown")
+	}
+}
@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"context"
+	"time"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// Default retry settings applied when the configuration leaves them unset
+const (
+	DefaultMaxAttempts    = 3
+	DefaultInitialBackoff = time.Second
+	DefaultMaxBackoff     = 30 * time.Second
+)
+
+// RetryPolicy decides whether and when failed operations are retried
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Retryable      map[types.ErrorCategory]bool
+}
+
+// NoRetry returns a policy that runs every operation exactly once
+func NoRetry() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+// NewRetryPolicy builds a policy from configuration, filling in defaults
+func NewRetryPolicy(cfg *types.RetryConfig) RetryPolicy {
+	policy := RetryPolicy{
+		MaxAttempts:    DefaultMaxAttempts,
+		InitialBackoff: DefaultInitialBackoff,
+		MaxBackoff:     DefaultMaxBackoff,
+		Retryable: map[types.ErrorCategory]bool{
+			types.ErrorNetwork: true,
+			types.ErrorTimeout: true,
+		},
+	}
+
+	if cfg == nil {
+		return policy
+	}
+
+	if cfg.MaxAttempts > 0 {
+		policy.MaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.InitialBackoff > 0 {
+		policy.InitialBackoff = cfg.InitialBackoff
+	}
+	if cfg.MaxBackoff > 0 {
+		policy.MaxBackoff = cfg.MaxBackoff
+	}
+	if len(cfg.Categories) > 0 {
+		policy.Retryable = make(map[types.ErrorCategory]bool, len(cfg.Categories))
+		for _, category := range cfg.Categories {
+			policy.Retryable[category] = true
+		}
+	}
+
+	return policy
+}
+
+// ShouldRetry reports whether a failure after the given attempt should be retried
+func (r RetryPolicy) ShouldRetry(category types.ErrorCategory, attempt int) bool {
+	return attempt < r.MaxAttempts && r.Retryable[category]
+}
+
+// Backoff returns the delay before the attempt following the given one
+func (r RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := r.InitialBackoff
+	for i := 1; i < attempt && (r.MaxBackoff <= 0 || delay < r.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if r.MaxBackoff > 0 && delay > r.MaxBackoff {
+		delay = r.MaxBackoff
+	}
+	return delay
+}
+
+// sleepContext waits for d, returning false if ctx is cancelled first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,147 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+func TestNewRetryPolicy_Defaults(t *testing.T) {
+	policy := NewRetryPolicy(nil)
+
+	if policy.MaxAttempts != DefaultMaxAttempts {
+		t.Errorf("expected %d attempts, got %d", DefaultMaxAttempts, policy.MaxAttempts)
+	}
+	if !policy.Retryable[types.ErrorNetwork] || !policy.Retryable[types.ErrorTimeout] {
+		t.Error("expected network and timeout to be retryable by default")
+	}
+	if policy.Retryable[types.ErrorAuth] {
+		t.Error("auth failures should not be retryable by default")
+	}
+}
+
+func TestNewRetryPolicy_FromConfig(t *testing.T) {
+	policy := NewRetryPolicy(&types.RetryConfig{
+		MaxAttempts:    5,
+		InitialBackoff: 10 * time.Millisecond,
+		Categories:     []types.ErrorCategory{types.ErrorConflict},
+	})
+
+	if policy.MaxAttempts != 5 || policy.InitialBackoff != 10*time.Millisecond {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+	if policy.MaxBackoff != DefaultMaxBackoff {
+		t.Errorf("expected default max backoff, got %s", policy.MaxBackoff)
+	}
+	if policy.Retryable[types.ErrorNetwork] || !policy.Retryable[types.ErrorConflict] {
+		t.Errorf("expected categories to replace defaults, got %v", policy.Retryable)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := policy.Backoff(i + 1); got != want {
+			t.Errorf("Backoff(%d) = %s, want %s", i+1, got, want)
+		}
+	}
+}
+
+// flakyHandler fails with the given category until it has been called failures times
+func flakyHandler(failures int32, category types.ErrorCategory, calls *int32) Handler {
+	return func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		if atomic.AddInt32(calls, 1) <= failures {
+			return types.NewOperationError(category, errors.New("transient"))
+		}
+		return nil
+	}
+}
+
+func TestExecute_RetriesRetryableFailures(t *testing.T) {
+	var calls int32
+	p := NewPool(1)
+	p.SetRetryPolicy(RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		Retryable:      map[types.ErrorCategory]bool{types.ErrorNetwork: true},
+	})
+	p.RegisterHandler("flaky", flakyHandler(2, types.ErrorNetwork, &calls))
+
+	for result := range p.Execute(context.Background(), []types.Operation{makeOp(makeRepo("r1"), "flaky")}) {
+		if !result.Success {
+			t.Errorf("expected success after retries, got %v", result.Error)
+		}
+		if result.Attempts != 3 {
+			t.Errorf("expected 3 attempts, got %d", result.Attempts)
+		}
+	}
+}
+
+func TestExecute_DoesNotRetryNonRetryableFailures(t *testing.T) {
+	var calls int32
+	p := NewPool(1)
+	p.SetRetryPolicy(NewRetryPolicy(&types.RetryConfig{InitialBackoff: time.Millisecond}))
+	p.RegisterHandler("flaky", flakyHandler(5, types.ErrorAuth, &calls))
+
+	for result := range p.Execute(context.Background(), []types.Operation{makeOp(makeRepo("r1"), "flaky")}) {
+		if result.Success {
+			t.Error("expected failure")
+		}
+		if result.Category != types.ErrorAuth {
+			t.Errorf("expected auth category, got %q", result.Category)
+		}
+		if result.Attempts != 1 {
+			t.Errorf("expected a single attempt, got %d", result.Attempts)
+		}
+	}
+}
+
+func TestExecute_GivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	p := NewPool(1)
+	p.SetRetryPolicy(NewRetryPolicy(&types.RetryConfig{MaxAttempts: 2, InitialBackoff: time.Millisecond}))
+	p.RegisterHandler("flaky", flakyHandler(5, types.ErrorNetwork, &calls))
+
+	for result := range p.Execute(context.Background(), []types.Operation{makeOp(makeRepo("r1"), "flaky")}) {
+		if result.Success || result.Attempts != 2 {
+			t.Errorf("expected failure after 2 attempts, got success=%v attempts=%d", result.Success, result.Attempts)
+		}
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("expected handler to run twice, ran %d times", calls)
+	}
+}
+
+func TestExecute_TimeoutIsCategorized(t *testing.T) {
+	p := NewPool(1)
+	p.SetTimeout(10 * time.Millisecond)
+	p.RegisterHandler("slow", func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	for result := range p.Execute(context.Background(), []types.Operation{makeOp(makeRepo("r1"), "slow")}) {
+		if result.Category != types.ErrorTimeout {
+			t.Errorf("expected timeout category, got %q", result.Category)
+		}
+	}
+}
+
+func TestExecute_RefusalsAreReportedAsRefused(t *testing.T) {
+	p := NewPool(1)
+	p.RegisterHandler("update", func(ctx context.Context, op *types.Operation, result *types.Result) error {
+		return types.NewOperationError(types.ErrorAhead, errors.New("ahead"))
+	})
+
+	for result := range p.Execute(context.Background(), []types.Operation{makeOp(makeRepo("r1"), "update")}) {
+		if result.State != types.ResultRefused || result.Category != types.ErrorAhead {
+			t.Errorf("expected refused/ahead, got %q/%q", result.State, result.Category)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package executor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// OrderByDependencies makes operations with these names wait for the
+// operations of their repository's dependsOn entries. Other operations, such
+// as read-only ones, run independently of dependsOn.
+func (p *Pool) OrderByDependencies(commands ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ordered == nil {
+		p.ordered = make(map[string]bool)
+	}
+	for _, command := range commands {
+		p.ordered[command] = true
+	}
+}
+
+// isOrdered reports whether an operation waits for its repository's dependencies
+func (p *Pool) isOrdered(command string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.ordered[command]
+}
+
+// dependencyPlan orders a batch of operations by their repositories' dependsOn
+// edges. Only operations for which ordered is true wait for their
+// dependencies; dependencies on repositories outside the batch are ignored.
+type dependencyPlan struct {
+	operations []types.Operation
+	waiting    []int   // unfinished dependencies per operation
+	dependents [][]int // operations waiting on each operation
+	settled    []bool  // operation has finished, been skipped or been cancelled
+}
+
+// newDependencyPlan builds the plan for a batch of operations
+func newDependencyPlan(operations []types.Operation, ordered func(command string) bool) *dependencyPlan {
+	plan := &dependencyPlan{
+		operations: operations,
+		waiting:    make([]int, len(operations)),
+		dependents: make([][]int, len(operations)),
+		settled:    make([]bool, len(operations)),
+	}
+
+	byName := make(map[string][]int)
+	for i, op := range operations {
+		if op.Repository != nil {
+			byName[op.Repository.Name] = append(byName[op.Repository.Name], i)
+		}
+	}
+
+	for i, op := range operations {
+		if op.Repository == nil || !ordered(op.Command) {
+			continue
+		}
+		for _, dep := range op.Repository.DependsOn {
+			for _, j := range byName[dep] {
+				if j == i {
+					continue
+				}
+				plan.waiting[i]++
+				plan.dependents[j] = append(plan.dependents[j], i)
+			}
+		}
+	}
+
+	return plan
+}
+
+// ready returns the operations that have no outstanding dependencies
+func (d *dependencyPlan) ready() []int {
+	var ready []int
+	for i := range d.operations {
+		if d.waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	return ready
+}
+
+// prioritize orders operation indices by descending repository priority,
+// keeping batch order among equal priorities
+func (d *dependencyPlan) prioritize(indices []int) {
+	sort.Slice(indices, func(a, b int) bool {
+		pa, pb := d.priority(indices[a]), d.priority(indices[b])
+		if pa != pb {
+			return pa > pb
+		}
+		return indices[a] < indices[b]
+	})
+}
+
+// priority returns the scheduling priority of an operation
+func (d *dependencyPlan) priority(i int) int {
+	if repo := d.operations[i].Repository; repo != nil {
+		return repo.Priority
+	}
+	return 0
+}
+
+// blocked returns the operations that can never start because their
+// dependencies form a cycle, in index order
+func (d *dependencyPlan) blocked() []int {
+	waiting := append([]int(nil), d.waiting...)
+	queue := d.ready()
+	reachable := make([]bool, len(d.operations))
+
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		reachable[i] = true
+		for _, next := range d.dependents[i] {
+			waiting[next]--
+			if waiting[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	var blocked []int
+	for i := range d.operations {
+		if !reachable[i] {
+			blocked = append(blocked, i)
+		}
+	}
+	return blocked
+}
+
+// succeed settles an operation and returns dependents that became ready
+func (d *dependencyPlan) succeed(i int) []int {
+	d.settled[i] = true
+
+	var ready []int
+	for _, next := range d.dependents[i] {
+		d.waiting[next]--
+		if d.waiting[next] == 0 && !d.settled[next] {
+			ready = append(ready, next)
+		}
+	}
+	return ready
+}
+
+// fail settles an operation and returns every transitive dependent, which
+// must now be skipped, in index order
+func (d *dependencyPlan) fail(i int) []int {
+	d.settled[i] = true
+
+	var skipped []int
+	queue := append([]int(nil), d.dependents[i]...)
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if d.settled[next] {
+			continue
+		}
+		d.settled[next] = true
+		skipped = append(skipped, next)
+		queue = append(queue, d.dependents[next]...)
+	}
+
+	sort.Ints(skipped)
+	return skipped
+}
+
+// settle marks an operation as finished without releasing its dependents
+func (d *dependencyPlan) settle(i int) {
+	d.settled[i] = true
+}
+
+// unsettled returns all operations that have not yet been settled
+func (d *dependencyPlan) unsettled() []int {
+	var pending []int
+	for i := range d.operations {
+		if !d.settled[i] {
+			pending = append(pending, i)
+		}
+	}
+	return pending
+}
+
+// skippedResult builds the result for an operation whose dependency did not succeed
+func skippedResult(op *types.Operation, failed *types.Operation) types.Result {
+	name := failed.Command
+	if failed.Repository != nil {
+		name = failed.Repository.Name
+	}
+
+	return types.Result{
+		Repository: op.Repository,
+		Operation:  op.Command,
+		State:      types.ResultSkipped,
+		Success:    false,
+		Error:      fmt.Errorf("skipped: dependency %s did not succeed", name),
+	}
+}
+
+// cycleResult builds the result for an operation caught in a dependency cycle
+func cycleResult(op *types.Operation) types.Result {
+	return types.Result{
+		Repository: op.Repository,
+		Operation:  op.Command,
+		State:      types.ResultSkipped,
+		Success:    false,
+		Error:      fmt.Errorf("skipped: dependency cycle involving %s", strings.Join(op.Repository.DependsOn, ", ")),
+	}
+}
+
+// cancelledResult builds the result for an operation that never started
+func cancelledResult(op *types.Operation, err error) types.Result {
+	return types.Result{
+		Repository: op.Repository,
+		Operation:  op.Command,
+		State:      types.ResultCancelled,
+		Success:    false,
+		Error:      err,
+	}
+}
@@ -0,0 +1,269 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+func makeDepRepo(name string, deps ...string) *types.Repository {
+	repo := makeRepo(name)
+	repo.DependsOn = deps
+	return repo
+}
+
+// orderRecorder is a handler that records start and finish order, failing for named repos
+type orderRecorder struct {
+	mu       sync.Mutex
+	started  []string
+	finished map[string]time.Time
+	fail     map[string]bool
+}
+
+func newOrderRecorder(fail ...string) *orderRecorder {
+	r := &orderRecorder{finished: make(map[string]time.Time), fail: make(map[string]bool)}
+	for _, name := range fail {
+		r.fail[name] = true
+	}
+	return r
+}
+
+func (r *orderRecorder) handler(ctx context.Context, op *types.Operation, result *types.Result) error {
+	r.mu.Lock()
+	r.started = append(r.started, op.Repository.Name)
+	r.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	r.mu.Lock()
+	r.finished[op.Repository.Name] = time.Now()
+	r.mu.Unlock()
+
+	if r.fail[op.Repository.Name] {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func (r *orderRecorder) startIndex(name string) int {
+	for i, n := range r.started {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func runOps(p *Pool, ops []types.Operation) map[string]types.Result {
+	results := make(map[string]types.Result)
+	for result := range p.Execute(context.Background(), ops) {
+		results[result.Repository.Name] = result
+	}
+	return results
+}
+
+func TestExecute_DependenciesRunFirst(t *testing.T) {
+	rec := newOrderRecorder()
+	p := NewPool(4)
+	p.RegisterHandler("build", rec.handler)
+	p.OrderByDependencies("build")
+
+	ops := []types.Operation{
+		makeOp(makeDepRepo("app", "svc", "lib"), "build"),
+		makeOp(makeDepRepo("svc", "lib"), "build"),
+		makeOp(makeDepRepo("lib"), "build"),
+	}
+	results := runOps(p, ops)
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if rec.startIndex("lib") > rec.startIndex("svc") || rec.startIndex("svc") > rec.startIndex("app") {
+		t.Errorf("expected lib, svc, app order, got %v", rec.started)
+	}
+}
+
+func TestExecute_IndependentBranchesRunInParallel(t *testing.T) {
+	m := &fakeManager{delay: 30 * time.Millisecond}
+	p := NewManagedPool(4, m)
+
+	ops := []types.Operation{
+		makeOp(makeDepRepo("a"), OpUpdate),
+		makeOp(makeDepRepo("b"), OpUpdate),
+		makeOp(makeDepRepo("c", "a"), OpUpdate),
+		makeOp(makeDepRepo("d", "b"), OpUpdate),
+	}
+	runOps(p, ops)
+
+	if m.maxSeen < 2 {
+		t.Errorf("expected independent branches to overlap, max active was %d", m.maxSeen)
+	}
+}
+
+func TestExecute_DependentsOfFailureAreSkipped(t *testing.T) {
+	rec := newOrderRecorder("lib")
+	p := NewPool(2)
+	p.RegisterHandler("build", rec.handler)
+	p.OrderByDependencies("build")
+
+	ops := []types.Operation{
+		makeOp(makeDepRepo("lib"), "build"),
+		makeOp(makeDepRepo("svc", "lib"), "build"),
+		makeOp(makeDepRepo("app", "svc"), "build"),
+		makeOp(makeDepRepo("other"), "build"),
+	}
+	results := runOps(p, ops)
+
+	if results["lib"].State != types.ResultFailed {
+		t.Errorf("expected lib to fail, got %q", results["lib"].State)
+	}
+	for _, name := range []string{"svc", "app"} {
+		if results[name].State != types.ResultSkipped {
+			t.Errorf("expected %s to be skipped, got %q", name, results[name].State)
+		}
+	}
+	if results["other"].State != types.ResultSucceeded {
+		t.Errorf("expected unrelated repo to succeed, got %q", results["other"].State)
+	}
+	if rec.startIndex("svc") != -1 || rec.startIndex("app") != -1 {
+		t.Errorf("skipped repositories should never start, started: %v", rec.started)
+	}
+}
+
+func TestExecute_DependencyOutsideBatchIsIgnored(t *testing.T) {
+	rec := newOrderRecorder()
+	p := NewPool(1)
+	p.RegisterHandler("build", rec.handler)
+	p.OrderByDependencies("build")
+
+	results := runOps(p, []types.Operation{makeOp(makeDepRepo("svc", "not-in-batch"), "build")})
+
+	if results["svc"].State != types.ResultSucceeded {
+		t.Errorf("expected success, got %q (%v)", results["svc"].State, results["svc"].Error)
+	}
+}
+
+func TestExecute_UnorderedOperationsIgnoreDependencies(t *testing.T) {
+	rec := newOrderRecorder("lib")
+	p := NewManagedPool(1, &fakeManager{})
+	p.RegisterHandler(OpStatus, rec.handler)
+
+	ops := []types.Operation{
+		makeOp(makeDepRepo("app", "lib"), OpStatus),
+		makeOp(makeDepRepo("lib"), OpStatus),
+		makeOp(makeDepRepo("a", "b"), OpStatus),
+		makeOp(makeDepRepo("b", "a"), OpStatus),
+	}
+	results := runOps(p, ops)
+
+	if results["app"].State != types.ResultSucceeded {
+		t.Errorf("expected app to run despite lib failing, got %q (%v)", results["app"].State, results["app"].Error)
+	}
+	if results["a"].State != types.ResultSucceeded || results["b"].State != types.ResultSucceeded {
+		t.Errorf("expected a dependency cycle not to skip status, got %q and %q", results["a"].State, results["b"].State)
+	}
+	if rec.startIndex("app") != 0 {
+		t.Errorf("expected app to start first in input order, got %v", rec.started)
+	}
+}
+
+func TestExecute_DependencyCycleIsSkipped(t *testing.T) {
+	rec := newOrderRecorder()
+	p := NewPool(2)
+	p.RegisterHandler("build", rec.handler)
+	p.OrderByDependencies("build")
+
+	ops := []types.Operation{
+		makeOp(makeDepRepo("a", "b"), "build"),
+		makeOp(makeDepRepo("b", "a"), "build"),
+		makeOp(makeDepRepo("c"), "build"),
+	}
+	results := runOps(p, ops)
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results["a"].State != types.ResultSkipped || results["b"].State != types.ResultSkipped {
+		t.Errorf("expected cyclic repositories to be skipped, got %q and %q", results["a"].State, results["b"].State)
+	}
+	if results["c"].State != types.ResultSucceeded {
+		t.Errorf("expected c to succeed, got %q", results["c"].State)
+	}
+}
+
+func TestExecute_CancelReportsUnstartedOperations(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := NewPool(1)
+	p.RegisterHandler("block", func(opCtx context.Context, op *types.Operation, result *types.Result) error {
+		cancel()
+		<-opCtx.Done()
+		return opCtx.Err()
+	})
+
+	ops := []types.Operation{
+		makeOp(makeRepo("r1"), "block"),
+		makeOp(makeRepo("r2"), "block"),
+		makeOp(makeRepo("r3"), "block"),
+	}
+
+	var summary Summary
+	for result := range p.Execute(ctx, ops) {
+		summary.Add(result)
+	}
+
+	if summary.Total != 3 {
+		t.Errorf("expected a result for every operation, got %d", summary.Total)
+	}
+	if summary.Cancelled != 3 {
+		t.Errorf("expected all operations cancelled, got %+v", summary)
+	}
+}
+
+func TestExecute_HigherPriorityStartsFirst(t *testing.T) {
+	rec := newOrderRecorder()
+	p := NewPool(1)
+	p.RegisterHandler("build", rec.handler)
+
+	low, mid, high := makeDepRepo("low"), makeDepRepo("mid"), makeDepRepo("high")
+	mid.Priority = 10
+	high.Priority = 100
+	runOps(p, []types.Operation{
+		makeOp(low, "build"),
+		makeOp(makeDepRepo("plain"), "build"),
+		makeOp(mid, "build"),
+		makeOp(high, "build"),
+	})
+
+	want := []string{"high", "mid", "low", "plain"}
+	for i, name := range want {
+		if rec.started[i] != name {
+			t.Fatalf("expected start order %v, got %v", want, rec.started)
+		}
+	}
+}
+
+func TestExecute_PriorityRespectsDependencies(t *testing.T) {
+	rec := newOrderRecorder()
+	p := NewPool(1)
+	p.RegisterHandler("build", rec.handler)
+	p.OrderByDependencies("build")
+
+	app := makeDepRepo("app", "lib")
+	app.Priority = 100
+	runOps(p, []types.Operation{
+		makeOp(app, "build"),
+		makeOp(makeDepRepo("lib"), "build"),
+		makeOp(makeDepRepo("other"), "build"),
+	})
+
+	if rec.startIndex("lib") > rec.startIndex("app") {
+		t.Errorf("expected dependency to run before its high-priority dependent, got %v", rec.started)
+	}
+	if rec.startIndex("app") > rec.startIndex("other") {
+		t.Errorf("expected released high-priority operation to start before lower priority work, got %v", rec.started)
+	}
+}
@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// Summary tallies operation results by their final state
+type Summary struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	TimedOut  int
+	Cancelled int
+	Skipped   int
+	Refused   int
+}
+
+// Add records a single result in the summary
+func (s *Summary) Add(result types.Result) {
+	s.Total++
+
+	switch result.State {
+	case types.ResultSucceeded:
+		s.Succeeded++
+	case types.ResultTimedOut:
+		s.TimedOut++
+	case types.ResultCancelled:
+		s.Cancelled++
+	case types.ResultSkipped:
+		s.Skipped++
+	case types.ResultRefused:
+		s.Refused++
+	default:
+		if result.Success {
+			s.Succeeded++
+		} else {
+			s.Failed++
+		}
+	}
+}
+
+// Failures returns the number of operations that ran and did not succeed
+func (s *Summary) Failures() int {
+	return s.Failed + s.TimedOut + s.Refused
+}
+
+// ReachedFailureLimit reports whether the failures have reached limit. A
+// limit of 0 or less is never reached.
+func (s *Summary) ReachedFailureLimit(limit int) bool {
+	return limit > 0 && s.Failures() >= limit
+}
+
+// Unsuccessful returns the number of operations that did not succeed for any reason
+func (s *Summary) Unsuccessful() int {
+	return s.Total - s.Succeeded
+}
+
+// String formats the summary as a single line, omitting empty categories
+func (s *Summary) String() string {
+	parts := []string{fmt.Sprintf("%d succeeded", s.Succeeded)}
+	if s.Failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", s.Failed))
+	}
+	if s.Refused > 0 {
+		parts = append(parts, fmt.Sprintf("%d refused", s.Refused))
+	}
+	if s.TimedOut > 0 {
+		parts = append(parts, fmt.Sprintf("%d timed out", s.TimedOut))
+	}
+	if s.Cancelled > 0 {
+		parts = append(parts, fmt.Sprintf("%d cancelled", s.Cancelled))
+	}
+	if s.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", s.Skipped))
+	}
+
+	return fmt.Sprintf("Summary: %s (%d total)", strings.Join(parts, ", "), s.Total)
+}
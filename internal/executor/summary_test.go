@@ -0,0 +1,76 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+func TestSummary_CountsByState(t *testing.T) {
+	var s Summary
+	s.Add(types.Result{State: types.ResultSucceeded, Success: true})
+	s.Add(types.Result{State: types.ResultFailed})
+	s.Add(types.Result{State: types.ResultTimedOut})
+	s.Add(types.Result{State: types.ResultCancelled})
+
+	if s.Total != 4 || s.Succeeded != 1 || s.Failed != 1 || s.TimedOut != 1 || s.Cancelled != 1 {
+		t.Errorf("unexpected counts: %+v", s)
+	}
+}
+
+func TestSummary_StringOmitsEmptyCategories(t *testing.T) {
+	var s Summary
+	s.Add(types.Result{State: types.ResultSucceeded, Success: true})
+	s.Add(types.Result{State: types.ResultTimedOut})
+
+	got := s.String()
+	if !strings.Contains(got, "1 succeeded") || !strings.Contains(got, "1 timed out") {
+		t.Errorf("unexpected summary: %q", got)
+	}
+	if strings.Contains(got, "failed") {
+		t.Errorf("summary should omit empty categories: %q", got)
+	}
+}
+
+func TestSummary_FailuresAndUnsuccessful(t *testing.T) {
+	var s Summary
+	s.Add(types.Result{State: types.ResultSucceeded, Success: true})
+	s.Add(types.Result{State: types.ResultFailed})
+	s.Add(types.Result{State: types.ResultTimedOut})
+	s.Add(types.Result{State: types.ResultSkipped})
+	s.Add(types.Result{State: types.ResultCancelled})
+
+	if s.Failures() != 2 {
+		t.Errorf("expected 2 failures, got %d", s.Failures())
+	}
+	if s.Unsuccessful() != 4 {
+		t.Errorf("expected 4 unsuccessful, got %d", s.Unsuccessful())
+	}
+}
+
+func TestSummary_ReachedFailureLimit(t *testing.T) {
+	var s Summary
+	s.Add(types.Result{State: types.ResultFailed})
+	s.Add(types.Result{State: types.ResultSkipped})
+
+	if s.ReachedFailureLimit(0) || s.ReachedFailureLimit(2) {
+		t.Errorf("expected one failure not to reach 0 (no limit) or 2: %+v", s)
+	}
+	s.Add(types.Result{State: types.ResultTimedOut})
+	if !s.ReachedFailureLimit(2) {
+		t.Errorf("expected two failures to reach a limit of 2: %+v", s)
+	}
+}
+
+func TestSummary_CountsRefused(t *testing.T) {
+	var s Summary
+	s.Add(types.Result{State: types.ResultRefused})
+
+	if s.Refused != 1 || s.Failures() != 1 {
+		t.Errorf("expected refusal to count as a failure: %+v", s)
+	}
+	if !strings.Contains(s.String(), "1 refused") {
+		t.Errorf("unexpected summary: %q", s.String())
+	}
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/LederWorks/gorepos/internal/state"
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// branchStateFile is the workspace state file caching detected default branches
+const branchStateFile = "branches.json"
+
+// DetectedBranch is a cached default branch detected from a repository's remote
+type DetectedBranch struct {
+	Branch     string    `json:"branch"`
+	URL        string    `json:"url"` // Remote URL the branch was detected for; a changed URL invalidates the entry
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// branchCache is the layout of the branch state file
+type branchCache struct {
+	Branches map[string]DetectedBranch `json:"branches"`
+}
+
+// LoadDetectedBranches returns the default branches cached in a workspace,
+// keyed by repository name. A workspace without a cache returns an empty map.
+func LoadDetectedBranches(basePath string) (map[string]DetectedBranch, error) {
+	var cache branchCache
+	if err := state.ReadJSON(state.Path(basePath, branchStateFile), &cache); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if cache.Branches == nil {
+		cache.Branches = make(map[string]DetectedBranch)
+	}
+	return cache.Branches, nil
+}
+
+// ResolveBranch returns the branch a repository tracks and whether it was
+// configured or detected. Without a configured branch the remote's default
+// branch is used, read from the workspace cache or detected and cached.
+func (m *Manager) ResolveBranch(ctx context.Context, repo *types.Repository) (string, types.BranchSource, error) {
+	if repo.Branch != "" {
+		return repo.Branch, types.BranchConfigured, nil
+	}
+
+	m.branchMu.Lock()
+	if m.branches == nil {
+		m.branches = m.loadBranchCache()
+	}
+	cached, ok := m.branches[repo.Name]
+	m.branchMu.Unlock()
+	if ok && cached.URL == repo.URL {
+		return cached.Branch, types.BranchDetected, nil
+	}
+
+	branch, err := m.detectDefaultBranch(ctx, repo)
+	if err != nil {
+		return "", "", err
+	}
+
+	m.branchMu.Lock()
+	defer m.branchMu.Unlock()
+	m.branches[repo.Name] = DetectedBranch{Branch: branch, URL: repo.URL, DetectedAt: time.Now()}
+	m.saveBranchCache()
+
+	return branch, types.BranchDetected, nil
+}
+
+// detectDefaultBranch asks git for the remote's HEAD, using the clone's
+// refs/remotes/origin/HEAD when present and ls-remote otherwise
+func (m *Manager) detectDefaultBranch(ctx context.Context, repo *types.Repository) (string, error) {
+	types.ReportProgress(ctx, "detecting default branch")
+
+	var output []byte
+	var err error
+	if m.Exists(repo) {
+		output, err = m.runGit(ctx, repo, "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD")
+		if branch := strings.TrimPrefix(strings.TrimSpace(string(output)), "origin/"); err == nil && branch != "" {
+			return branch, nil
+		}
+		output, err = m.runGit(ctx, repo, "ls-remote", "--symref", "origin", "HEAD")
+	} else {
+		output, err = m.runGitIn(ctx, repo, "", "ls-remote", "--symref", repo.URL, "HEAD")
+	}
+	if err != nil {
+		return "", newGitError("ls-remote", err, output)
+	}
+
+	if branch := parseSymref(string(output)); branch != "" {
+		return branch, nil
+	}
+	return "", fmt.Errorf("could not detect the default branch of %s; set branch in the configuration", repo.Name)
+}
+
+// parseSymref extracts the branch from "ref: refs/heads/<branch>\tHEAD" in
+// git ls-remote --symref output
+func parseSymref(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.HasPrefix(line, "ref: ") {
+			continue
+		}
+		ref, target, found := strings.Cut(strings.TrimPrefix(line, "ref: "), "\t")
+		if found && strings.TrimSpace(target) == "HEAD" {
+			return strings.TrimPrefix(ref, "refs/heads/")
+		}
+	}
+	return ""
+}
+
+// loadBranchCache reads the workspace cache, starting empty when it is
+// missing or unreadable. Without a base path there is no workspace to cache in.
+func (m *Manager) loadBranchCache() map[string]DetectedBranch {
+	if m.basePath == "" {
+		return make(map[string]DetectedBranch)
+	}
+	branches, err := LoadDetectedBranches(m.basePath)
+	if err != nil {
+		return make(map[string]DetectedBranch)
+	}
+	return branches
+}
+
+// saveBranchCache writes the cache back to the workspace. The cache only saves
+// detection work, so a failed write is not an error. Callers hold branchMu.
+func (m *Manager) saveBranchCache() {
+	if m.basePath == "" {
+		return
+	}
+	state.WriteJSON(state.Path(m.basePath, branchStateFile), branchCache{Branches: m.branches})
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+func TestParseSymref(t *testing.T) {
+	output := "ref: refs/heads/develop\tHEAD\n0123456789abcdef\tHEAD\n"
+	if got := parseSymref(output); got != "develop" {
+		t.Errorf("expected develop, got %q", got)
+	}
+	if got := parseSymref("0123456789abcdef\tHEAD\n"); got != "" {
+		t.Errorf("expected no branch without a symref line, got %q", got)
+	}
+}
+
+func TestResolveBranch_Configured(t *testing.T) {
+	m := NewManager("")
+	branch, source, err := m.ResolveBranch(context.Background(), &types.Repository{Name: "r", Branch: "release"})
+	if err != nil || branch != "release" || source != types.BranchConfigured {
+		t.Errorf("expected configured release, got %q %q %v", branch, source, err)
+	}
+}
+
+func TestResolveBranch_DetectsFromClone(t *testing.T) {
+	src := initLocalRepo(t)
+	run(t, src, "git", "branch", "-M", "develop")
+	dest := cloneLocalRepo(t, src)
+
+	m := NewManager("")
+	repo := &types.Repository{Name: "test", Path: dest, URL: src}
+
+	branch, source, err := m.ResolveBranch(context.Background(), repo)
+	if err != nil || branch != "develop" || source != types.BranchDetected {
+		t.Errorf("expected detected develop, got %q %q %v", branch, source, err)
+	}
+}
+
+func TestResolveBranch_DetectsRemoteAndCaches(t *testing.T) {
+	src := initLocalRepo(t)
+	run(t, src, "git", "branch", "-M", "develop")
+	basePath := t.TempDir()
+
+	m := NewManager(basePath)
+	repo := &types.Repository{Name: "test", Path: "not-cloned", URL: src}
+
+	branch, _, err := m.ResolveBranch(context.Background(), repo)
+	if err != nil || branch != "develop" {
+		t.Fatalf("expected develop from ls-remote, got %q %v", branch, err)
+	}
+
+	cached, err := LoadDetectedBranches(basePath)
+	if err != nil {
+		t.Fatalf("load cache: %v", err)
+	}
+	if cached["test"].Branch != "develop" || cached["test"].URL != src {
+		t.Errorf("expected develop cached for %s, got %+v", src, cached["test"])
+	}
+
+	// A new manager reads the cache instead of asking the remote again
+	run(t, src, "git", "branch", "-M", "trunk")
+	branch, _, err = NewManager(basePath).ResolveBranch(context.Background(), repo)
+	if err != nil || branch != "develop" {
+		t.Errorf("expected cached develop, got %q %v", branch, err)
+	}
+
+	// A changed URL invalidates the entry
+	moved := filepath.Join(t.TempDir(), "moved")
+	run(t, "", "git", "clone", "--bare", src, moved)
+	repo.URL = moved
+	branch, _, err = NewManager(basePath).ResolveBranch(context.Background(), repo)
+	if err != nil || branch != "trunk" {
+		t.Errorf("expected trunk after URL change, got %q %v", branch, err)
+	}
+}
+
+func TestStatus_ReportsDetectedBranch(t *testing.T) {
+	src := initLocalRepo(t)
+	run(t, src, "git", "branch", "-M", "develop")
+	dest := cloneLocalRepo(t, src)
+	commitFile(t, src, "remote.txt")
+	run(t, dest, "git", "fetch", "origin")
+
+	m := NewManager("")
+	status, err := m.Status(context.Background(), &types.Repository{Name: "test", Path: dest, URL: src})
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.Branch != "develop" || status.BranchSource != types.BranchDetected {
+		t.Errorf("expected detected develop, got %q %q", status.Branch, status.BranchSource)
+	}
+	if status.AheadBehind == nil || status.AheadBehind.Behind != 1 {
+		t.Errorf("expected 1 behind origin/develop, got %+v", status.AheadBehind)
+	}
+}
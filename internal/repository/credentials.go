@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// defaultTokenUser is sent with access tokens when no tokenUser is configured
+const defaultTokenUser = "x-access-token"
+
+// redactedSecret replaces secrets in git output
+const redactedSecret = "***"
+
+// SetCredentials sets the global credential configuration applied to git
+// commands, including its per-host overrides
+func (m *Manager) SetCredentials(cfg *types.CredentialConfig) {
+	m.credentials = cfg
+}
+
+// gitCredentials is the credential setup of one repository translated into git
+type gitCredentials struct {
+	args    []string // -c options placed before the git subcommand
+	env     []string // Variables added to the environment
+	secrets []string // Values that must never appear in output
+}
+
+// ResolveCredentials merges credential settings for a repository: the global
+// settings, then the most specific host pattern matching its URL, then the
+// repository's own settings. Each level only overrides the fields it sets.
+func ResolveCredentials(global *types.CredentialConfig, repo *types.Repository) types.CredentialConfig {
+	var resolved types.CredentialConfig
+	if global != nil {
+		resolved = mergeCredentials(resolved, *global)
+		if host, ok := matchCredentialHost(global.Hosts, HostFromURL(repo.URL)); ok {
+			resolved = mergeCredentials(resolved, host)
+		}
+	}
+	if repo.Credentials != nil {
+		resolved = mergeCredentials(resolved, *repo.Credentials)
+	}
+	resolved.Hosts = nil
+	return resolved
+}
+
+// mergeCredentials overrides the fields of base that override sets
+func mergeCredentials(base, override types.CredentialConfig) types.CredentialConfig {
+	if override.SSHKeyPath != "" {
+		base.SSHKeyPath = override.SSHKeyPath
+	}
+	if override.GitCredHelper != "" {
+		base.GitCredHelper = override.GitCredHelper
+	}
+	if override.TokenEnvVar != "" {
+		base.TokenEnvVar = override.TokenEnvVar
+	}
+	if override.TokenUser != "" {
+		base.TokenUser = override.TokenUser
+	}
+	return base
+}
+
+// matchCredentialHost returns the host entry for host. An exact entry wins over
+// patterns; among patterns the longest match wins.
+func matchCredentialHost(hosts map[string]types.CredentialConfig, host string) (types.CredentialConfig, bool) {
+	if host == "" || len(hosts) == 0 {
+		return types.CredentialConfig{}, false
+	}
+
+	var matches []string
+	for pattern, cfg := range hosts {
+		if strings.ToLower(pattern) == host {
+			return cfg, true
+		}
+		if ok, _ := path.Match(strings.ToLower(pattern), host); ok {
+			matches = append(matches, pattern)
+		}
+	}
+	if len(matches) == 0 {
+		return types.CredentialConfig{}, false
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if len(matches[i]) != len(matches[j]) {
+			return len(matches[i]) > len(matches[j])
+		}
+		return matches[i] < matches[j]
+	})
+	return hosts[matches[0]], true
+}
+
+// gitCredentials translates the repository's credentials into git options and
+// environment. The token travels in the environment as a header scoped to the
+// repository's host, so it is neither on the command line nor sent elsewhere.
+func (m *Manager) gitCredentials(repo *types.Repository) gitCredentials {
+	cfg := ResolveCredentials(m.credentials, repo)
+	var creds gitCredentials
+
+	if cfg.SSHKeyPath != "" {
+		creds.env = append(creds.env, "GIT_SSH_COMMAND=ssh -i "+shellQuote(expandHome(cfg.SSHKeyPath))+" -o IdentitiesOnly=yes")
+	}
+
+	if cfg.GitCredHelper != "" {
+		// An empty helper clears helpers from git's own configuration first
+		creds.args = append(creds.args, "-c", "credential.helper=", "-c", "credential.helper="+cfg.GitCredHelper)
+	}
+
+	if cfg.TokenEnvVar != "" {
+		token := repo.Environment[cfg.TokenEnvVar]
+		if token == "" {
+			token = os.Getenv(cfg.TokenEnvVar)
+		}
+		remote, err := ParseRemoteURL(repo.URL)
+		if token != "" && err == nil && (remote.Scheme == "https" || remote.Scheme == "http") {
+			user := cfg.TokenUser
+			if user == "" {
+				user = defaultTokenUser
+			}
+			encoded := base64.StdEncoding.EncodeToString([]byte(user + ":" + token))
+
+			origin := remote.Scheme + "://" + remote.Host
+			if remote.Port != "" {
+				origin += ":" + remote.Port
+			}
+			creds.env = append(creds.env, configEnv(
+				"http."+origin+"/.extraHeader", "Authorization: Basic "+encoded)...)
+			creds.secrets = append(creds.secrets, token, encoded)
+		}
+	}
+
+	return creds
+}
+
+// configEnv passes a git config entry through GIT_CONFIG_KEY_n/VALUE_n,
+// appending to any entries already in the process environment
+func configEnv(key, value string) []string {
+	count, _ := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+	return []string{
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", count, key),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", count, value),
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", count+1),
+	}
+}
+
+// redact replaces every secret in output
+func (c gitCredentials) redact(output []byte) []byte {
+	for _, secret := range c.secrets {
+		output = []byte(strings.ReplaceAll(string(output), secret, redactedSecret))
+	}
+	return output
+}
+
+// expandHome expands a leading ~/ to the user's home directory
+func expandHome(p string) string {
+	if !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, p[2:])
+}
+
+// shellQuote quotes s for the shell git runs GIT_SSH_COMMAND with
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+func TestResolveCredentials_Precedence(t *testing.T) {
+	global := &types.CredentialConfig{
+		SSHKeyPath:    "/keys/default",
+		GitCredHelper: "store",
+		TokenEnvVar:   "DEFAULT_TOKEN",
+		Hosts: map[string]types.CredentialConfig{
+			"*.example.com":     {TokenEnvVar: "EXAMPLE_TOKEN"},
+			"*.git.example.com": {SSHKeyPath: "/keys/git"},
+			"GitHub.com":        {TokenEnvVar: "GITHUB_TOKEN"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		repo types.Repository
+		want types.CredentialConfig
+	}{
+		{
+			name: "global only",
+			repo: types.Repository{URL: "https://gitlab.com/org/repo.git"},
+			want: types.CredentialConfig{SSHKeyPath: "/keys/default", GitCredHelper: "store", TokenEnvVar: "DEFAULT_TOKEN"},
+		},
+		{
+			name: "exact host, case-insensitive",
+			repo: types.Repository{URL: "git@github.com:org/repo.git"},
+			want: types.CredentialConfig{SSHKeyPath: "/keys/default", GitCredHelper: "store", TokenEnvVar: "GITHUB_TOKEN"},
+		},
+		{
+			name: "longest pattern wins",
+			repo: types.Repository{URL: "https://a.git.example.com/org/repo.git"},
+			want: types.CredentialConfig{SSHKeyPath: "/keys/git", GitCredHelper: "store", TokenEnvVar: "DEFAULT_TOKEN"},
+		},
+		{
+			name: "repository overrides host",
+			repo: types.Repository{
+				URL:         "https://a.example.com/org/repo.git",
+				Credentials: &types.CredentialConfig{GitCredHelper: "cache"},
+			},
+			want: types.CredentialConfig{SSHKeyPath: "/keys/default", GitCredHelper: "cache", TokenEnvVar: "EXAMPLE_TOKEN"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveCredentials(global, &tt.repo)
+			if got.SSHKeyPath != tt.want.SSHKeyPath || got.GitCredHelper != tt.want.GitCredHelper || got.TokenEnvVar != tt.want.TokenEnvVar {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitCredentials_Translation(t *testing.T) {
+	t.Setenv("GOREPOS_TEST_TOKEN", "s3cret-token")
+	t.Setenv("GIT_CONFIG_COUNT", "")
+
+	m := NewManager("")
+	m.SetCredentials(&types.CredentialConfig{
+		SSHKeyPath:    "/keys/id_ed25519",
+		GitCredHelper: "store",
+		TokenEnvVar:   "GOREPOS_TEST_TOKEN",
+	})
+
+	creds := m.gitCredentials(&types.Repository{URL: "https://github.com/org/repo.git"})
+	if strings.Join(creds.args, " ") != "-c credential.helper= -c credential.helper=store" {
+		t.Errorf("unexpected args %v", creds.args)
+	}
+
+	env := strings.Join(creds.env, "\n")
+	if !strings.Contains(env, "GIT_SSH_COMMAND=ssh -i '/keys/id_ed25519' -o IdentitiesOnly=yes") {
+		t.Errorf("expected GIT_SSH_COMMAND in %q", env)
+	}
+	encoded := base64.StdEncoding.EncodeToString([]byte("x-access-token:s3cret-token"))
+	if !strings.Contains(env, "GIT_CONFIG_KEY_0=http.https://github.com/.extraHeader") ||
+		!strings.Contains(env, "GIT_CONFIG_VALUE_0=Authorization: Basic "+encoded) {
+		t.Errorf("expected a host-scoped auth header in %q", env)
+	}
+	for _, arg := range creds.args {
+		if strings.Contains(arg, "s3cret-token") || strings.Contains(arg, encoded) {
+			t.Error("token must not be passed on the command line")
+		}
+	}
+
+	ssh := m.gitCredentials(&types.Repository{URL: "git@github.com:org/repo.git"})
+	if strings.Contains(strings.Join(ssh.env, "\n"), "extraHeader") {
+		t.Error("expected no auth header for SSH remotes")
+	}
+}
+
+func TestGitCredentials_HeaderScopedAndRedacted(t *testing.T) {
+	t.Setenv("GOREPOS_TEST_TOKEN", "s3cret-token")
+	t.Setenv("GIT_CONFIG_COUNT", "")
+	dir := initLocalRepo(t)
+
+	m := NewManager("")
+	m.SetCredentials(&types.CredentialConfig{TokenEnvVar: "GOREPOS_TEST_TOKEN"})
+	repo := &types.Repository{Name: "test", Path: dir, URL: "https://git.example.com/org/repo.git"}
+	ctx := context.Background()
+
+	output, err := m.runGit(ctx, repo, "config", "--get-urlmatch", "http.extraHeader", "https://git.example.com/org/other.git")
+	if err != nil {
+		t.Fatalf("expected the header for the repository's host: %v", err)
+	}
+	if got := strings.TrimSpace(string(output)); got != "Authorization: Basic ***" {
+		t.Errorf("expected a redacted header, got %q", got)
+	}
+
+	if _, err := m.runGit(ctx, repo, "config", "--get-urlmatch", "http.extraHeader", "https://elsewhere.example.com/org/repo.git"); err == nil {
+		t.Error("expected no header for other hosts")
+	}
+
+	result, err := m.Execute(ctx, repo, "printenv", "GOREPOS_TEST_TOKEN")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if strings.Contains(result.Output, "s3cret-token") {
+		t.Errorf("expected token to be redacted from output, got %q", result.Output)
+	}
+}
+
+func TestExecute_OmitsCredentialEnvironment(t *testing.T) {
+	t.Setenv("GOREPOS_TEST_TOKEN", "s3cret-token")
+	t.Setenv("GIT_CONFIG_COUNT", "")
+	t.Setenv("GIT_SSH_COMMAND", "")
+	dir := initLocalRepo(t)
+
+	m := NewManager("")
+	m.SetCredentials(&types.CredentialConfig{SSHKeyPath: "/keys/id_ed25519", TokenEnvVar: "GOREPOS_TEST_TOKEN"})
+	repo := &types.Repository{Name: "test", Path: dir, URL: "https://git.example.com/org/repo.git"}
+
+	result, err := m.Execute(context.Background(), repo, "sh", "-c", "echo ssh=$GIT_SSH_COMMAND count=$GIT_CONFIG_COUNT value=$GIT_CONFIG_VALUE_0")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got := strings.TrimSpace(result.Output); got != "ssh= count= value=" {
+		t.Errorf("expected no credential variables in the command's environment, got %q", got)
+	}
+
+	// git commands still authenticate
+	if _, err := m.runGit(context.Background(), repo, "config", "--get-urlmatch", "http.extraHeader", repo.URL); err != nil {
+		t.Errorf("expected git to receive the auth header: %v", err)
+	}
+}
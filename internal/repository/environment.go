@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// EnvLayer names where an environment variable of a repository came from
+type EnvLayer string
+
+const (
+	EnvProcess     EnvLayer = "process"     // Inherited from the gorepos process
+	EnvGlobal      EnvLayer = "global"      // global.environment of the merged configuration
+	EnvScope       EnvLayer = "scope"       // global.environment of the file declaring the repository or one including it
+	EnvCredentials EnvLayer = "credentials" // Set from credential settings, for git commands only
+	EnvRepository  EnvLayer = "repository"  // The repository's own environment
+)
+
+// EnvVar is one variable of a repository's effective environment
+type EnvVar struct {
+	Name  string
+	Value string
+	Layer EnvLayer // The layer that set the value; later layers override earlier ones
+}
+
+// SetGlobalEnvironment sets the global environment layered between the process
+// environment and each repository's own
+func (m *Manager) SetGlobalEnvironment(env map[string]string) {
+	m.globalEnv = env
+}
+
+// Environment returns the repository's effective environment sorted by name.
+// Layers apply in order: process, global, scope, credentials, repository.
+// Configured values are expanded as templates over the repository, so
+// "{{ .Name }}" and "{{ .Path }}" refer to its name and path. Credential
+// secrets are redacted in every layer.
+func (m *Manager) Environment(repo *types.Repository) []EnvVar {
+	creds := m.gitCredentials(repo)
+	vars := make(map[string]EnvVar)
+	set := func(layer EnvLayer, name, value string) {
+		vars[name] = EnvVar{Name: name, Value: value, Layer: layer}
+	}
+
+	for _, entry := range os.Environ() {
+		if name, value, ok := strings.Cut(entry, "="); ok {
+			set(EnvProcess, name, value)
+		}
+	}
+	for _, layer := range m.configuredEnvironment(repo) {
+		for name, value := range layer.vars {
+			// The root file's scope repeats the global layer; keep crediting global
+			if existing, ok := vars[name]; ok && layer.layer == EnvScope && existing.Layer == EnvGlobal && existing.Value == value {
+				continue
+			}
+			set(layer.layer, name, value)
+		}
+	}
+
+	for _, entry := range creds.env {
+		if name, value, ok := strings.Cut(entry, "="); ok {
+			set(EnvCredentials, name, value)
+		}
+	}
+	for name, value := range repo.Environment {
+		set(EnvRepository, name, types.ExpandEnvTemplate(value, repo))
+	}
+
+	result := make([]EnvVar, 0, len(vars))
+	for _, v := range vars {
+		v.Value = string(creds.redact([]byte(v.Value)))
+		result = append(result, v)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// envLayer is one configured environment layer with expanded values
+type envLayer struct {
+	layer EnvLayer
+	vars  map[string]string
+}
+
+// configuredEnvironment returns the global and scope layers expanded for the
+// repository. The repository layer is applied after credentials, separately.
+func (m *Manager) configuredEnvironment(repo *types.Repository) []envLayer {
+	layers := []envLayer{
+		{EnvGlobal, m.globalEnv},
+		{EnvScope, repo.ScopeEnvironment},
+	}
+	for i, layer := range layers {
+		expanded := make(map[string]string, len(layer.vars))
+		for name, value := range layer.vars {
+			expanded[name] = types.ExpandEnvTemplate(value, repo)
+		}
+		layers[i].vars = expanded
+	}
+	return layers
+}
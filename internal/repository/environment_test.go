@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+func TestEnvironment_Layers(t *testing.T) {
+	t.Setenv("GOREPOS_TEST_PROCESS", "process")
+	t.Setenv("GOREPOS_TEST_OVERRIDDEN", "process")
+
+	m := NewManager("")
+	m.SetGlobalEnvironment(map[string]string{
+		"GOREPOS_TEST_OVERRIDDEN": "global",
+		"GOREPOS_TEST_CACHE":      "/cache/{{ .Name }}",
+		"GOREPOS_TEST_SCOPED":     "global",
+	})
+	repo := &types.Repository{
+		Name:             "app",
+		Path:             "team/app",
+		ScopeEnvironment: map[string]string{"GOREPOS_TEST_SCOPED": "scope", "GOREPOS_TEST_CACHE": "/cache/{{ .Name }}"},
+		Environment:      map[string]string{"GOREPOS_TEST_REPO": "{{ .Path }}/bin"},
+	}
+
+	got := make(map[string]EnvVar)
+	for _, v := range m.Environment(repo) {
+		got[v.Name] = v
+	}
+
+	want := map[string]EnvVar{
+		"GOREPOS_TEST_PROCESS":    {Value: "process", Layer: EnvProcess},
+		"GOREPOS_TEST_OVERRIDDEN": {Value: "global", Layer: EnvGlobal},
+		"GOREPOS_TEST_CACHE":      {Value: "/cache/app", Layer: EnvGlobal},
+		"GOREPOS_TEST_SCOPED":     {Value: "scope", Layer: EnvScope},
+		"GOREPOS_TEST_REPO":       {Value: "team/app/bin", Layer: EnvRepository},
+	}
+	for name, w := range want {
+		if g := got[name]; g.Value != w.Value || g.Layer != w.Layer {
+			t.Errorf("%s: got %q (%s), want %q (%s)", name, g.Value, g.Layer, w.Value, w.Layer)
+		}
+	}
+}
+
+func TestEnvironment_RedactsTokenInEveryLayer(t *testing.T) {
+	t.Setenv("GIT_CONFIG_COUNT", "")
+	t.Setenv("GOREPOS_TEST_PROCESS_COPY", "supersecret123")
+
+	m := NewManager("")
+	m.SetCredentials(&types.CredentialConfig{TokenEnvVar: "MY_TOKEN"})
+	repo := &types.Repository{
+		Name:        "app",
+		URL:         "https://github.com/org/app.git",
+		Environment: map[string]string{"MY_TOKEN": "supersecret123"},
+	}
+
+	got := make(map[string]EnvVar)
+	for _, v := range m.Environment(repo) {
+		if strings.Contains(v.Value, "supersecret123") {
+			t.Errorf("%s (%s) shows the token: %q", v.Name, v.Layer, v.Value)
+		}
+		got[v.Name] = v
+	}
+	if g := got["MY_TOKEN"]; g.Value != redactedSecret || g.Layer != EnvRepository {
+		t.Errorf("expected a redacted repository value, got %q (%s)", g.Value, g.Layer)
+	}
+	if g := got["GOREPOS_TEST_PROCESS_COPY"]; g.Value != redactedSecret || g.Layer != EnvProcess {
+		t.Errorf("expected a redacted process value, got %q (%s)", g.Value, g.Layer)
+	}
+}
+
+func TestBuildEnvironment_AppliesLayersInOrder(t *testing.T) {
+	t.Setenv("GOREPOS_TEST_VAR", "process")
+	dir := initLocalRepo(t)
+
+	m := NewManager("")
+	m.SetGlobalEnvironment(map[string]string{"GOREPOS_TEST_VAR": "global", "GOREPOS_TEST_NAME": "{{ .Name }}"})
+	repo := &types.Repository{
+		Name:             "app",
+		Path:             dir,
+		ScopeEnvironment: map[string]string{"GOREPOS_TEST_VAR": "scope"},
+	}
+
+	result, err := m.Execute(context.Background(), repo, "sh", "-c", "echo $GOREPOS_TEST_VAR $GOREPOS_TEST_NAME")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got := strings.TrimSpace(result.Output); got != "scope app" {
+		t.Errorf("expected scope value and expanded name, got %q", got)
+	}
+
+	repo.Environment = map[string]string{"GOREPOS_TEST_VAR": "repository"}
+	result, err = m.Execute(context.Background(), repo, "sh", "-c", "echo $GOREPOS_TEST_VAR")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got := strings.TrimSpace(result.Output); got != "repository" {
+		t.Errorf("expected repository value to win, got %q", got)
+	}
+}
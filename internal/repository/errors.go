@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// GitError is returned when a git invocation fails
+type GitError struct {
+	Command  string // git subcommand that failed, e.g. "fetch"
+	Output   string
+	Err      error
+	Category types.ErrorCategory
+}
+
+// newGitError builds a GitError and classifies it from git's output
+func newGitError(command string, err error, output []byte) *GitError {
+	return &GitError{
+		Command:  command,
+		Output:   string(output),
+		Err:      err,
+		Category: classifyGitOutput(string(output)),
+	}
+}
+
+func (e *GitError) Error() string {
+	return fmt.Sprintf("git %s failed: %v\nOutput: %s", e.Command, e.Err, e.Output)
+}
+
+func (e *GitError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCategory returns the classified failure category
+func (e *GitError) ErrorCategory() types.ErrorCategory {
+	return e.Category
+}
+
+// gitErrorPatterns maps lower-cased git output fragments to categories, checked in order
+var gitErrorPatterns = []struct {
+	category types.ErrorCategory
+	patterns []string
+}{
+	{types.ErrorAuth, []string{
+		"authentication failed",
+		"permission denied",
+		"could not read username",
+		"could not read password",
+		"invalid username or password",
+		"access denied",
+		"host key verification failed",
+		"the requested url returned error: 401",
+		"the requested url returned error: 403",
+	}},
+	{types.ErrorNotFound, []string{
+		"repository not found",
+		"does not appear to be a git repository",
+		"couldn't find remote ref",
+		"remote branch",
+		"the requested url returned error: 404",
+		"does not exist",
+		"unknown revision",
+	}},
+	{types.ErrorNetwork, []string{
+		"could not resolve host",
+		"temporary failure in name resolution",
+		"connection timed out",
+		"operation timed out",
+		"connection refused",
+		"connection reset",
+		"network is unreachable",
+		"the remote end hung up unexpectedly",
+		"early eof",
+		"rpc failed",
+		"gnutls_handshake",
+		"ssl_read",
+		"ssl_connect",
+		"ssl_error_syscall",
+		"the requested url returned error: 429",
+		"the requested url returned error: 500",
+		"the requested url returned error: 502",
+		"the requested url returned error: 503",
+		"the requested url returned error: 504",
+	}},
+	{types.ErrorDirty, []string{
+		"your local changes",
+		"uncommitted changes",
+		"please commit your changes or stash them",
+		"untracked working tree files would be",
+	}},
+	{types.ErrorConflict, []string{
+		"conflict",
+		"needs merge",
+		"not possible to fast-forward",
+		"diverging branches",
+		"unmerged files",
+	}},
+}
+
+// classifyGitOutput returns the category that best matches git's error output
+func classifyGitOutput(output string) types.ErrorCategory {
+	lower := strings.ToLower(output)
+	for _, group := range gitErrorPatterns {
+		for _, pattern := range group.patterns {
+			if strings.Contains(lower, pattern) {
+				return group.category
+			}
+		}
+	}
+	return types.ErrorUnknown
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+func TestClassifyGitOutput(t *testing.T) {
+	cases := []struct {
+		output   string
+		expected types.ErrorCategory
+	}{
+		{"fatal: unable to access 'https://x/': Could not resolve host: x", types.ErrorNetwork},
+		{"fatal: the remote end hung up unexpectedly", types.ErrorNetwork},
+		{"remote: Invalid username or password.\nfatal: Authentication failed", types.ErrorAuth},
+		{"git@github.com: Permission denied (publickey).", types.ErrorAuth},
+		{"remote: Repository not found.", types.ErrorNotFound},
+		{"fatal: couldn't find remote ref feature/x", types.ErrorNotFound},
+		{"error: Your local changes to the following files would be overwritten by merge", types.ErrorDirty},
+		{"CONFLICT (content): Merge conflict in main.go", types.ErrorConflict},
+		{"fatal: Not possible to fast-forward, aborting.", types.ErrorConflict},
+		{"something unexpected", types.ErrorUnknown},
+	}
+
+	for _, tc := range cases {
+		if got := classifyGitOutput(tc.output); got != tc.expected {
+			t.Errorf("classifyGitOutput(%q) = %q, want %q", tc.output, got, tc.expected)
+		}
+	}
+}
+
+func TestGitError_PreservesMessageAndCategory(t *testing.T) {
+	inner := errors.New("exit status 128")
+	err := newGitError("fetch", inner, []byte("fatal: Could not resolve host: example.com"))
+
+	if err.Error() != "git fetch failed: exit status 128\nOutput: fatal: Could not resolve host: example.com" {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+	if !errors.Is(err, inner) {
+		t.Error("expected GitError to unwrap to the underlying error")
+	}
+	if types.CategoryOf(err) != types.ErrorNetwork {
+		t.Errorf("expected network category, got %q", types.CategoryOf(err))
+	}
+}
+
+func TestClone_InvalidURL_IsNotFound(t *testing.T) {
+	m := NewManager("")
+	repo := &types.Repository{
+		Name: "test",
+		Path: filepath.Join(t.TempDir(), "repo"),
+		URL:  "/nonexistent/url",
+	}
+
+	err := m.Clone(context.Background(), repo)
+	if err == nil {
+		t.Fatal("expected error for invalid URL")
+	}
+	if category := types.CategoryOf(err); category != types.ErrorNotFound {
+		t.Errorf("expected not_found category, got %q (%v)", category, err)
+	}
+}
+
+func TestUpdate_DirtyRepo_IsDirty(t *testing.T) {
+	src := initLocalRepo(t)
+	dest := cloneLocalRepo(t, src)
+	run(t, dest, "sh", "-c", "echo x > dirty.txt && git add dirty.txt")
+
+	m := NewManager("")
+	_, err := m.Update(context.Background(), &types.Repository{Name: "test", Path: dest, Branch: "master"})
+	if types.CategoryOf(err) != types.ErrorDirty {
+		t.Errorf("expected dirty category, got %q (%v)", types.CategoryOf(err), err)
+	}
+}
@@ -1,12 +1,17 @@
 package repository
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/LederWorks/gorepos/pkg/types"
@@ -14,7 +19,21 @@ import (
 
 // Manager implements the RepositoryManager interface
 type Manager struct {
-	basePath string
+	basePath      string
+	updateOptions UpdateOptions
+	credentials   *types.CredentialConfig // Global credentials with their host overrides
+	globalEnv     map[string]string       // global.environment, layered under each repository's
+	statusOptions StatusOptions
+	statusCache   *statusCache // Set by EnableStatusCache
+
+	branchMu sync.Mutex
+	branches map[string]DetectedBranch // Detected default branches, loaded lazily from workspace state
+}
+
+// UpdateOptions control how Update treats local state it would otherwise refuse to overwrite
+type UpdateOptions struct {
+	Force     bool // Discard unpushed commits and switch away from other branches
+	Autostash bool // Stash uncommitted changes around every update
 }
 
 // NewManager creates a new repository manager
@@ -24,6 +43,25 @@ func NewManager(basePath string) *Manager {
 	}
 }
 
+// NewManagerFromConfig creates a repository manager applying the global
+// base path, credentials and environment
+func NewManagerFromConfig(global *types.GlobalConfig) *Manager {
+	m := NewManager(global.BasePath)
+	m.SetCredentials(global.Credentials)
+	m.SetGlobalEnvironment(global.Environment)
+	return m
+}
+
+// SetUpdateOptions sets the options used by Update
+func (m *Manager) SetUpdateOptions(opts UpdateOptions) {
+	m.updateOptions = opts
+}
+
+// SetStatusOptions sets the options used by Status
+func (m *Manager) SetStatusOptions(opts StatusOptions) {
+	m.statusOptions = opts
+}
+
 // Clone clones a repository if it doesn't exist
 func (m *Manager) Clone(ctx context.Context, repo *types.Repository) error {
 	repoPath := m.getRepoPath(repo)
@@ -37,157 +75,185 @@ func (m *Manager) Clone(ctx context.Context, repo *types.Repository) error {
 		return fmt.Errorf("failed to create parent directory: %w", err)
 	}
 
+	if isMirror(repo) {
+		return m.cloneMirror(ctx, repo, repoPath)
+	}
+
 	args := []string{"clone"}
 	if repo.Branch != "" {
 		args = append(args, "-b", repo.Branch)
 	}
+	args = append(args, cloneArgs(repo.Clone)...)
+	if repo.Submodules == types.SubmodulesRecursive {
+		args = append(args, "--recurse-submodules")
+		if repo.Clone != nil && repo.Clone.Depth > 0 {
+			args = append(args, "--shallow-submodules")
+		}
+	}
 	args = append(args, repo.URL, repoPath)
 
-	cmd := exec.CommandContext(ctx, "git", args...)
-	cmd.Env = m.buildEnvironment(repo)
+	types.ReportProgress(ctx, "cloning")
+	if output, err := m.runGitIn(ctx, repo, "", args...); err != nil {
+		return newGitError("clone", err, output)
+	}
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("git clone failed: %w\nOutput: %s", err, string(output))
+	if len(repo.Remotes) > 0 {
+		if _, err := m.EnsureRemotes(ctx, repo); err != nil {
+			return err
+		}
+		types.ReportProgress(ctx, "fetching remotes")
+		if err := m.fetchRemotes(ctx, repo); err != nil {
+			return err
+		}
 	}
 
-	return nil
-}
+	if opts := repo.Clone; opts != nil && len(opts.SparsePaths) > 0 {
+		types.ReportProgress(ctx, "setting sparse paths")
+		sparseArgs := append([]string{"sparse-checkout", "set", "--cone"}, opts.SparsePaths...)
+		if output, err := m.runGit(ctx, repo, sparseArgs...); err != nil {
+			return newGitError("sparse-checkout", err, output)
+		}
+	}
 
-// Update updates an existing repository
-func (m *Manager) Update(ctx context.Context, repo *types.Repository) error {
-	if !m.Exists(repo) {
-		return fmt.Errorf("repository does not exist at %s", m.getRepoPath(repo))
+	if _, err := m.pullLFS(ctx, repo); err != nil {
+		return err
 	}
 
-	repoPath := m.getRepoPath(repo)
+	if _, err := m.EnsureWorktrees(ctx, repo); err != nil {
+		return err
+	}
 
-	// Fetch latest changes
-	cmd := exec.CommandContext(ctx, "git", "fetch", "origin")
-	cmd.Dir = repoPath
-	cmd.Env = m.buildEnvironment(repo)
+	return nil
+}
 
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git fetch failed: %w\nOutput: %s", err, string(output))
+// cloneArgs translates clone options into git clone flags. Sparse clones
+// start with only top-level files; Clone then sets the sparse paths.
+func cloneArgs(opts *types.CloneOptions) []string {
+	if opts == nil {
+		return nil
 	}
 
-	// Reset to origin branch if clean
-	status, err := m.Status(ctx, repo)
-	if err != nil {
-		return fmt.Errorf("failed to check repository status: %w", err)
+	var args []string
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
 	}
-
-	if !status.IsClean {
-		return fmt.Errorf("repository has uncommitted changes, cannot update")
+	if opts.Filter != "" {
+		args = append(args, "--filter="+opts.Filter)
 	}
-
-	// Reset to origin branch
-	targetBranch := repo.Branch
-	if targetBranch == "" {
-		targetBranch = "main"
+	if opts.SingleBranchEnabled() {
+		args = append(args, "--single-branch")
 	}
-
-	cmd = exec.CommandContext(ctx, "git", "reset", "--hard", fmt.Sprintf("origin/%s", targetBranch))
-	cmd.Dir = repoPath
-	cmd.Env = m.buildEnvironment(repo)
-
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git reset failed: %w\nOutput: %s", err, string(output))
+	if len(opts.SparsePaths) > 0 {
+		args = append(args, "--sparse")
 	}
-
-	return nil
+	return args
 }
 
-// Status returns the current status of a repository
+// Status returns the current status of a repository. Working tree state,
+// the checked out branch, ahead/behind counts and stashes come from a single
+// git status call. With the status cache enabled, each repository is read
+// once per run.
 func (m *Manager) Status(ctx context.Context, repo *types.Repository) (*types.RepoStatus, error) {
+	if m.statusCache != nil {
+		return m.statusCache.get(m.getRepoPath(repo), func() (*types.RepoStatus, error) {
+			return m.readStatus(ctx, repo)
+		})
+	}
+	return m.readStatus(ctx, repo)
+}
+
+// readStatus reads the repository's status from git, bypassing the cache
+func (m *Manager) readStatus(ctx context.Context, repo *types.Repository) (*types.RepoStatus, error) {
 	if !m.Exists(repo) {
-		return nil, fmt.Errorf("repository does not exist at %s", m.getRepoPath(repo))
+		return nil, types.NewOperationError(types.ErrorNotFound, fmt.Errorf("repository does not exist at %s", m.getRepoPath(repo)))
+	}
+	if isMirror(repo) {
+		status, err := m.mirrorStatus(ctx, repo)
+		if err == nil {
+			status.OriginURL, status.OriginMismatch = m.OriginDrift(ctx, repo)
+		}
+		return status, err
 	}
 
 	repoPath := m.getRepoPath(repo)
 	status := &types.RepoStatus{
 		Path: repoPath,
 	}
+	status.OriginURL, status.OriginMismatch = m.OriginDrift(ctx, repo)
 
-	// Get current branch
-	cmd := exec.CommandContext(ctx, "git", "branch", "--show-current")
-	cmd.Dir = repoPath
-	cmd.Env = m.buildEnvironment(repo)
-
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get current branch: %w", err)
-	}
-	status.CurrentBranch = strings.TrimSpace(string(output))
-
-	// Check if working tree is clean
-	cmd = exec.CommandContext(ctx, "git", "status", "--porcelain")
-	cmd.Dir = repoPath
-	cmd.Env = m.buildEnvironment(repo)
-
-	output, err = cmd.Output()
+	output, err := m.gitOutput(ctx, repo, statusArgs...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get status: %w", err)
+		return nil, fmt.Errorf("failed to get status: %w", newGitError("status", err, output))
 	}
-
-	statusOutput := strings.TrimSpace(string(output))
-	status.IsClean = statusOutput == ""
-
-	if !status.IsClean {
-		lines := strings.Split(statusOutput, "\n")
-		for _, line := range lines {
-			if line != "" {
-				// Extract filename from git status output
-				parts := strings.SplitN(line, " ", 3)
-				if len(parts) >= 3 {
-					status.UncommittedFiles = append(status.UncommittedFiles, strings.TrimSpace(parts[2]))
-				}
-			}
+	tree := parseStatusV2(string(output))
+
+	status.CurrentBranch = tree.branch
+	status.Detached = tree.detached
+	status.Upstream = tree.upstream
+	status.StashCount = tree.stashes
+	status.Staged = tree.staged
+	status.Unstaged = tree.unstaged
+	status.Untracked = tree.untracked
+	status.Conflicted = tree.conflicted
+	status.UncommittedFiles = tree.changedPaths()
+	status.IsClean = len(status.UncommittedFiles) == 0
+	if tree.head != "" {
+		status.LastCommit = &types.CommitInfo{Hash: tree.head}
+		if m.statusOptions.CommitDetails {
+			status.LastCommit = m.lastCommit(ctx, repo, tree.head)
 		}
 	}
 
-	// Get ahead/behind info
-	targetBranch := repo.Branch
-	if targetBranch == "" {
-		targetBranch = "main"
-	}
-
-	cmd = exec.CommandContext(ctx, "git", "rev-list", "--count", "--left-right", fmt.Sprintf("HEAD...origin/%s", targetBranch))
-	cmd.Dir = repoPath
-	cmd.Env = m.buildEnvironment(repo)
+	// Flag clones that do not hold the full history or tree. Both are read
+	// from the git directory; submodules and worktrees only cost a git call
+	// when the repository has any.
+	status.Shallow = m.isShallow(repo)
+	status.Sparse = m.isSparse(repo)
+	status.Submodules = m.submoduleStatus(ctx, repo)
+	status.Worktrees = m.worktreeStatus(ctx, repo)
 
-	output, err = cmd.Output()
-	if err == nil {
-		parts := strings.Split(strings.TrimSpace(string(output)), "\t")
-		if len(parts) == 2 {
-			ahead := 0
-			behind := 0
-			fmt.Sscanf(parts[0], "%d", &ahead)
-			fmt.Sscanf(parts[1], "%d", &behind)
-
-			status.AheadBehind = &types.BranchComparison{
-				Ahead:  ahead,
-				Behind: behind,
-			}
-		}
+	// Get ahead/behind info against the configured or detected branch
+	targetBranch, source, err := m.ResolveBranch(ctx, repo)
+	if err != nil {
+		return status, nil
 	}
+	status.Branch = targetBranch
+	status.BranchSource = source
+
+	// git status already compared the branch with its upstream; count
+	// separately only when that is not the tracked branch on origin
+	var ok bool
+	if status.AheadBehind, ok = tree.compareUpstream("origin/" + targetBranch); !ok {
+		status.AheadBehind = m.compareRefs(ctx, repo, "HEAD", "origin/"+targetBranch)
+	}
+	status.Remotes = m.remoteStatus(ctx, repo, targetBranch, status.AheadBehind)
 
 	return status, nil
 }
 
-// Execute runs a custom command in the repository directory
+// Execute runs a custom command in the repository directory. The command gets
+// the repository's environment without the credential layer.
 func (m *Manager) Execute(ctx context.Context, repo *types.Repository, command string, args ...string) (*types.Result, error) {
+	return m.ExecuteStream(ctx, repo, nil, command, args...)
+}
+
+// ExecuteStream runs a custom command in the repository directory like
+// Execute, passing each line of its combined output to onLine as it is
+// written. The full output is also returned in the result. A nil onLine
+// only collects.
+func (m *Manager) ExecuteStream(ctx context.Context, repo *types.Repository, onLine func(line string), command string, args ...string) (*types.Result, error) {
 	startTime := time.Now()
 	result := &types.Result{
 		Repository: repo,
 		Operation:  command,
 		StartTime:  startTime,
+		ExitCode:   -1,
 	}
 
 	repoPath := m.getRepoPath(repo)
 
 	if !m.Exists(repo) {
-		result.Error = fmt.Errorf("repository does not exist at %s", repoPath)
+		result.Error = types.NewOperationError(types.ErrorNotFound, fmt.Errorf("repository does not exist at %s", repoPath))
 		result.Duration = time.Since(startTime)
 		return result, result.Error
 	}
@@ -196,15 +262,47 @@ func (m *Manager) Execute(ctx context.Context, repo *types.Repository, command s
 	cmd.Dir = repoPath
 	cmd.Env = m.buildEnvironment(repo)
 
-	output, err := cmd.CombinedOutput()
-	result.Output = string(output)
+	creds := m.gitCredentials(repo)
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	// Children left holding the output open must not keep a cancelled command waiting
+	cmd.WaitDelay = time.Second
+
+	var output strings.Builder
+	copied := make(chan struct{})
+	go func() {
+		defer close(copied)
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := string(creds.redact(scanner.Bytes()))
+			output.WriteString(line + "\n")
+			if onLine != nil {
+				onLine(line)
+			}
+		}
+		// Keep draining after an overlong line so the command is not blocked
+		io.Copy(io.Discard, reader)
+	}()
+
+	err := cmd.Run()
+	writer.Close()
+	<-copied
+
+	result.Output = output.String()
 	result.Duration = time.Since(startTime)
 
-	if err != nil {
-		result.Error = err
-		result.Success = false
-	} else {
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		result.ExitCode = 0
 		result.Success = true
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+		result.Error = err
+	default:
+		result.Error = err
 	}
 
 	return result, nil
@@ -212,20 +310,28 @@ func (m *Manager) Execute(ctx context.Context, repo *types.Repository, command s
 
 // Exists checks if a repository exists at the configured path
 func (m *Manager) Exists(repo *types.Repository) bool {
-	repoPath := m.getRepoPath(repo)
-	gitDir := filepath.Join(repoPath, ".git")
-
-	// Check if it's a git repository
-	if stat, err := os.Stat(gitDir); err == nil {
-		return stat.IsDir()
+	if isMirror(repo) {
+		return isBareRepository(m.getRepoPath(repo))
 	}
+	return isGitCheckout(m.getRepoPath(repo))
+}
 
-	// Check if it's a git worktree
-	if _, err := os.Stat(gitDir); err == nil {
+// isGitCheckout reports whether path holds a git working tree. Main clones
+// have a .git directory; worktrees and submodules have a .git file that
+// points at their git directory.
+func isGitCheckout(path string) bool {
+	gitDir := filepath.Join(path, ".git")
+
+	stat, err := os.Stat(gitDir)
+	if err != nil {
+		return false
+	}
+	if stat.IsDir() {
 		return true
 	}
 
-	return false
+	data, err := os.ReadFile(gitDir)
+	return err == nil && strings.HasPrefix(string(data), "gitdir:")
 }
 
 // getRepoPath returns the absolute path for a repository
@@ -241,14 +347,67 @@ func (m *Manager) getRepoPath(repo *types.Repository) string {
 	return repo.Path
 }
 
-// buildEnvironment builds the environment variables for git commands
+// buildEnvironment builds the environment variables for commands run in the
+// repository from the process environment, the global and scope layers and the
+// repository's own variables, in that order; later entries win. Credentials
+// are left out so commands other than git cannot read them.
 func (m *Manager) buildEnvironment(repo *types.Repository) []string {
+	return m.layeredEnvironment(repo, nil)
+}
+
+// gitEnvironment builds the environment variables for git commands: the
+// buildEnvironment layers with the credential layer before the repository's
+func (m *Manager) gitEnvironment(repo *types.Repository, creds gitCredentials) []string {
+	return m.layeredEnvironment(repo, creds.env)
+}
+
+// layeredEnvironment applies the configured layers over the process
+// environment, with credEnv between the scope and repository layers
+func (m *Manager) layeredEnvironment(repo *types.Repository, credEnv []string) []string {
 	env := os.Environ()
+	for _, layer := range m.configuredEnvironment(repo) {
+		for key, value := range layer.vars {
+			env = append(env, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+	env = append(env, credEnv...)
 
 	// Add repository-specific environment variables
 	for key, value := range repo.Environment {
-		env = append(env, fmt.Sprintf("%s=%s", key, value))
+		env = append(env, fmt.Sprintf("%s=%s", key, types.ExpandEnvTemplate(value, repo)))
 	}
 
 	return env
 }
+
+// runGit runs a git command in the repository and returns its combined output
+func (m *Manager) runGit(ctx context.Context, repo *types.Repository, args ...string) ([]byte, error) {
+	return m.runGitIn(ctx, repo, m.getRepoPath(repo), args...)
+}
+
+// runGitIn runs a git command in dir with the repository's credentials and
+// returns its combined output with secrets redacted. An empty dir runs git
+// outside the repository, for clone and ls-remote.
+func (m *Manager) runGitIn(ctx context.Context, repo *types.Repository, dir string, args ...string) ([]byte, error) {
+	creds := m.gitCredentials(repo)
+	cmd := exec.CommandContext(ctx, "git", append(creds.args, args...)...)
+	cmd.Dir = dir
+	cmd.Env = m.gitEnvironment(repo, creds)
+	output, err := cmd.CombinedOutput()
+	return creds.redact(output), err
+}
+
+// gitOutput runs a git command in the repository and returns only its
+// standard output, for output that is parsed. On failure it returns git's
+// standard error instead.
+func (m *Manager) gitOutput(ctx context.Context, repo *types.Repository, args ...string) ([]byte, error) {
+	creds := m.gitCredentials(repo)
+	cmd := exec.CommandContext(ctx, "git", append(creds.args, args...)...)
+	cmd.Dir = m.getRepoPath(repo)
+	cmd.Env = m.gitEnvironment(repo, creds)
+	output, err := cmd.Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		output = exitErr.Stderr
+	}
+	return creds.redact(output), err
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/LederWorks/gorepos/pkg/types"
@@ -25,7 +26,7 @@ func initBareRepo(t *testing.T) string {
 }
 
 // initLocalRepo creates a local git repo with an initial commit and returns its path.
-func initLocalRepo(t *testing.T) string {
+func initLocalRepo(t testing.TB) string {
 	t.Helper()
 	dir := t.TempDir()
 
@@ -48,7 +49,7 @@ func initLocalRepo(t *testing.T) string {
 }
 
 // cloneLocalRepo clones src into a new temp dir and returns the clone path.
-func cloneLocalRepo(t *testing.T, src string) string {
+func cloneLocalRepo(t testing.TB, src string) string {
 	t.Helper()
 	dest := filepath.Join(t.TempDir(), "clone")
 	run(t, "", "git", "clone", src, dest)
@@ -56,7 +57,7 @@ func cloneLocalRepo(t *testing.T, src string) string {
 }
 
 // run runs a command in dir (empty means current dir), failing the test on error.
-func run(t *testing.T, dir string, name string, args ...string) {
+func run(t testing.TB, dir string, name string, args ...string) {
 	t.Helper()
 	cmd := exec.Command(name, args...)
 	if dir != "" {
@@ -68,6 +69,11 @@ func run(t *testing.T, dir string, name string, args ...string) {
 	}
 }
 
+// boolPtr returns a pointer to v, for optional settings.
+func boolPtr(v bool) *bool {
+	return &v
+}
+
 // --- NewManager ---
 
 func TestNewManager(t *testing.T) {
@@ -293,6 +299,73 @@ func TestStatus_HasCurrentBranch(t *testing.T) {
 	}
 }
 
+func TestClone_ShallowSparse(t *testing.T) {
+	src := initLocalRepo(t)
+	for _, dir := range []string{"app", "docs"} {
+		if err := os.MkdirAll(filepath.Join(src, dir), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		commitFile(t, src, filepath.Join(dir, "file.txt"))
+	}
+	dest := filepath.Join(t.TempDir(), "clone")
+
+	m := NewManager("")
+	repo := &types.Repository{
+		Name: "test",
+		Path: dest,
+		URL:  "file://" + src,
+		Clone: &types.CloneOptions{
+			Depth:        1,
+			SingleBranch: boolPtr(true),
+			SparsePaths:  []string{"app"},
+		},
+	}
+	if err := m.Clone(context.Background(), repo); err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dest, "app", "file.txt")); err != nil {
+		t.Errorf("expected sparse path to be checked out: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "docs")); !os.IsNotExist(err) {
+		t.Errorf("expected docs to be left out of the sparse checkout, got %v", err)
+	}
+
+	status, err := m.Status(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !status.Shallow || !status.Sparse {
+		t.Errorf("expected shallow sparse clone, got shallow=%v sparse=%v", status.Shallow, status.Sparse)
+	}
+
+	// Update fast-forwards and keeps the clone shallow and sparse
+	commitFile(t, src, filepath.Join("app", "more.txt"))
+	if _, err := m.Update(context.Background(), repo); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	status, err = m.Status(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !status.Shallow || !status.Sparse {
+		t.Errorf("expected clone to stay shallow and sparse, got shallow=%v sparse=%v", status.Shallow, status.Sparse)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "app", "more.txt")); err != nil {
+		t.Errorf("expected update to check out new files in the sparse path: %v", err)
+	}
+}
+
+func TestCloneArgs(t *testing.T) {
+	got := strings.Join(cloneArgs(&types.CloneOptions{Depth: 5, Filter: "blob:none", SingleBranch: boolPtr(true), SparsePaths: []string{"src"}}), " ")
+	if want := "--depth 5 --filter=blob:none --single-branch --sparse"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if args := cloneArgs(nil); args != nil {
+		t.Errorf("expected no args without options, got %v", args)
+	}
+}
+
 // --- Update ---
 
 func TestUpdate_NonExistentRepo(t *testing.T) {
@@ -301,7 +374,7 @@ func TestUpdate_NonExistentRepo(t *testing.T) {
 		Name: "test",
 		Path: "/nonexistent/repo",
 	}
-	if err := m.Update(context.Background(), repo); err == nil {
+	if _, err := m.Update(context.Background(), repo); err == nil {
 		t.Error("expected error for non-existent repo")
 	}
 }
@@ -323,7 +396,7 @@ func TestUpdate_DirtyRepo(t *testing.T) {
 		Branch: "master",
 	}
 
-	if err := m.Update(context.Background(), repo); err == nil {
+	if _, err := m.Update(context.Background(), repo); err == nil {
 		t.Error("expected error updating dirty repo")
 	}
 }
@@ -344,11 +417,338 @@ func TestUpdate_CleanRepo(t *testing.T) {
 		Branch: "master",
 	}
 
-	if err := m.Update(context.Background(), repo); err != nil {
+	if _, err := m.Update(context.Background(), repo); err != nil {
 		t.Fatalf("Update failed on clean repo: %v", err)
 	}
 }
 
+// commitFile commits a new file named name in dir.
+func commitFile(t *testing.T, dir, name string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	run(t, dir, "git", "add", name)
+	run(t, dir, "git", "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "--no-gpg-sign", "-m", name)
+}
+
+// headCommit returns the commit hash HEAD points at in dir.
+func headCommit(t *testing.T, dir string) string {
+	t.Helper()
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("rev-parse HEAD: %v", err)
+	}
+	return string(out)
+}
+
+func TestUpdate_ResetRefusesWhenAhead(t *testing.T) {
+	src := initLocalRepo(t)
+	dest := cloneLocalRepo(t, src)
+	commitFile(t, dest, "local.txt")
+	before := headCommit(t, dest)
+
+	m := NewManager("")
+	repo := &types.Repository{Name: "test", Path: dest, URL: src, Branch: "master", UpdateStrategy: types.StrategyReset}
+
+	_, err := m.Update(context.Background(), repo)
+	if types.CategoryOf(err) != types.ErrorAhead {
+		t.Fatalf("expected ahead refusal, got %v", err)
+	}
+	if headCommit(t, dest) != before {
+		t.Error("refused update must not move HEAD")
+	}
+}
+
+func TestUpdate_RefusesWhenDiverged(t *testing.T) {
+	src := initLocalRepo(t)
+	dest := cloneLocalRepo(t, src)
+	commitFile(t, dest, "local.txt")
+	commitFile(t, src, "remote.txt")
+
+	m := NewManager("")
+	repo := &types.Repository{Name: "test", Path: dest, URL: src, Branch: "master"}
+
+	if _, err := m.Update(context.Background(), repo); types.CategoryOf(err) != types.ErrorDiverged {
+		t.Fatalf("expected diverged refusal, got %v", err)
+	}
+}
+
+func TestUpdate_RefusesOnOtherBranch(t *testing.T) {
+	src := initLocalRepo(t)
+	dest := cloneLocalRepo(t, src)
+	run(t, dest, "git", "checkout", "-b", "feature")
+
+	m := NewManager("")
+	repo := &types.Repository{Name: "test", Path: dest, URL: src, Branch: "master"}
+
+	if _, err := m.Update(context.Background(), repo); types.CategoryOf(err) != types.ErrorWrongBranch {
+		t.Fatalf("expected wrong branch refusal, got %v", err)
+	}
+}
+
+func TestUpdate_FastForwardsWhenBehind(t *testing.T) {
+	src := initLocalRepo(t)
+	dest := cloneLocalRepo(t, src)
+	commitFile(t, src, "remote.txt")
+
+	m := NewManager("")
+	repo := &types.Repository{Name: "test", Path: dest, URL: src, Branch: "master"}
+
+	report, err := m.Update(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("expected update to succeed when only behind, got %v", err)
+	}
+	if headCommit(t, dest) != headCommit(t, src) {
+		t.Error("expected clone to match remote after update")
+	}
+	if got := report.String(); got != "ff-only: fast-forwarded 1 commit(s)" {
+		t.Errorf("unexpected report %q", got)
+	}
+}
+
+func TestUpdate_FFOnlyKeepsUnpushedCommits(t *testing.T) {
+	src := initLocalRepo(t)
+	dest := cloneLocalRepo(t, src)
+	commitFile(t, dest, "local.txt")
+	before := headCommit(t, dest)
+
+	m := NewManager("")
+	repo := &types.Repository{Name: "test", Path: dest, URL: src, Branch: "master"}
+
+	report, err := m.Update(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("expected update with only local commits to succeed, got %v", err)
+	}
+	if headCommit(t, dest) != before {
+		t.Error("expected local commits to be kept")
+	}
+	if report.Local != 1 || report.Incoming != 0 {
+		t.Errorf("expected 1 local and 0 incoming commits, got %+v", report)
+	}
+}
+
+func TestUpdate_RebaseReplaysLocalCommits(t *testing.T) {
+	src := initLocalRepo(t)
+	dest := cloneLocalRepo(t, src)
+	commitFile(t, dest, "local.txt")
+	commitFile(t, src, "remote.txt")
+
+	run(t, dest, "git", "config", "user.email", "test@test.com")
+	run(t, dest, "git", "config", "user.name", "Test")
+
+	m := NewManager("")
+	repo := &types.Repository{Name: "test", Path: dest, URL: src, Branch: "master", UpdateStrategy: types.StrategyRebase}
+
+	report, err := m.Update(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("expected rebase to succeed, got %v", err)
+	}
+	if got := report.String(); got != "rebase: rebased 1 local commit(s) onto 1 new commit(s)" {
+		t.Errorf("unexpected report %q", got)
+	}
+	run(t, dest, "git", "merge-base", "--is-ancestor", "origin/master", "HEAD")
+	for _, name := range []string{"local.txt", "remote.txt"} {
+		if _, err := os.Stat(filepath.Join(dest, name)); err != nil {
+			t.Errorf("expected %s after rebase: %v", name, err)
+		}
+	}
+}
+
+func TestUpdate_MergeCreatesMergeCommit(t *testing.T) {
+	src := initLocalRepo(t)
+	dest := cloneLocalRepo(t, src)
+	commitFile(t, dest, "local.txt")
+	commitFile(t, src, "remote.txt")
+
+	run(t, dest, "git", "config", "user.email", "test@test.com")
+	run(t, dest, "git", "config", "user.name", "Test")
+
+	m := NewManager("")
+	repo := &types.Repository{Name: "test", Path: dest, URL: src, Branch: "master", UpdateStrategy: types.StrategyMerge}
+
+	report, err := m.Update(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("expected merge to succeed, got %v", err)
+	}
+	if report.Action != "merged 1 new commit(s) with 1 local commit(s)" {
+		t.Errorf("unexpected action %q", report.Action)
+	}
+	run(t, dest, "git", "rev-parse", "--verify", "HEAD^2")
+}
+
+func TestUpdate_RebaseConflictAborts(t *testing.T) {
+	src := initLocalRepo(t)
+	dest := cloneLocalRepo(t, src)
+	commitFile(t, dest, "same.txt")
+	if err := os.WriteFile(filepath.Join(src, "same.txt"), []byte("remote"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	run(t, src, "git", "add", "same.txt")
+	run(t, src, "git", "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "--no-gpg-sign", "-m", "remote")
+	before := headCommit(t, dest)
+
+	run(t, dest, "git", "config", "user.email", "test@test.com")
+	run(t, dest, "git", "config", "user.name", "Test")
+
+	m := NewManager("")
+	repo := &types.Repository{Name: "test", Path: dest, URL: src, Branch: "master", UpdateStrategy: types.StrategyRebase}
+
+	if _, err := m.Update(context.Background(), repo); types.CategoryOf(err) != types.ErrorConflict {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+	if headCommit(t, dest) != before {
+		t.Error("expected aborted rebase to restore HEAD")
+	}
+	status, err := m.Status(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !status.IsClean {
+		t.Errorf("expected clean tree after abort, got %v", status.UncommittedFiles)
+	}
+}
+
+func TestUpdate_ForceOverridesRefusals(t *testing.T) {
+	src := initLocalRepo(t)
+	dest := cloneLocalRepo(t, src)
+	commitFile(t, dest, "local.txt")
+	run(t, dest, "git", "checkout", "-b", "feature")
+
+	m := NewManager("")
+	m.SetUpdateOptions(UpdateOptions{Force: true})
+	repo := &types.Repository{Name: "test", Path: dest, URL: src, Branch: "master"}
+
+	if _, err := m.Update(context.Background(), repo); err != nil {
+		t.Fatalf("expected forced update to succeed, got %v", err)
+	}
+	status, err := m.Status(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.CurrentBranch != "master" {
+		t.Errorf("expected forced update to switch to master, on %q", status.CurrentBranch)
+	}
+	if headCommit(t, dest) != headCommit(t, src) {
+		t.Error("expected forced update to reset to the remote")
+	}
+}
+
+// stashList returns the output of git stash list in dir.
+func stashList(t *testing.T, dir string) string {
+	t.Helper()
+	out, err := exec.Command("git", "-C", dir, "stash", "list").Output()
+	if err != nil {
+		t.Fatalf("stash list: %v", err)
+	}
+	return string(out)
+}
+
+func TestUpdate_AutostashReappliesChanges(t *testing.T) {
+	src := initLocalRepo(t)
+	dest := cloneLocalRepo(t, src)
+	run(t, dest, "git", "config", "user.email", "test@test.com")
+	run(t, dest, "git", "config", "user.name", "Test")
+	commitFile(t, src, "remote.txt")
+	if err := os.WriteFile(filepath.Join(dest, "wip.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	m := NewManager("")
+	m.SetUpdateOptions(UpdateOptions{Autostash: true})
+	repo := &types.Repository{Name: "test", Path: dest, URL: src, Branch: "master"}
+
+	report, err := m.Update(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("expected autostash update to succeed, got %v", err)
+	}
+	if !report.Stashed || headCommit(t, dest) != headCommit(t, src) {
+		t.Errorf("expected stashed fast-forward, got %+v", report)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "wip.txt")); err != nil || string(data) != "wip" {
+		t.Errorf("expected local changes to be re-applied, got %q (%v)", data, err)
+	}
+	if out := stashList(t, dest); out != "" {
+		t.Errorf("expected stash to be dropped, got %q", out)
+	}
+}
+
+func TestUpdate_AutostashConflictKeepsStash(t *testing.T) {
+	src := initLocalRepo(t)
+	dest := cloneLocalRepo(t, src)
+	run(t, dest, "git", "config", "user.email", "test@test.com")
+	run(t, dest, "git", "config", "user.name", "Test")
+	commitFile(t, src, "shared.txt")
+	run(t, dest, "git", "fetch", "origin")
+	run(t, dest, "git", "merge", "--ff-only", "origin/master")
+	if err := os.WriteFile(filepath.Join(src, "shared.txt"), []byte("remote"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	run(t, src, "git", "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "--no-gpg-sign", "-am", "remote edit")
+	if err := os.WriteFile(filepath.Join(dest, "shared.txt"), []byte("local"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	m := NewManager("")
+	repo := &types.Repository{Name: "test", Path: dest, URL: src, Branch: "master", Autostash: boolPtr(true)}
+
+	_, err := m.Update(context.Background(), repo)
+	if types.CategoryOf(err) != types.ErrorConflict || !strings.Contains(err.Error(), "stash@{0}") {
+		t.Fatalf("expected conflict naming the stash, got %v", err)
+	}
+	if headCommit(t, dest) != headCommit(t, src) {
+		t.Error("expected the update itself to be applied")
+	}
+	if out := stashList(t, dest); !strings.Contains(out, "gorepos autostash") {
+		t.Errorf("expected autostash entry to be kept, got %q", out)
+	}
+	status, err := m.Status(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !status.IsClean {
+		t.Errorf("expected clean tree after conflict, got %v", status.UncommittedFiles)
+	}
+}
+
+func TestUpdate_AutostashLeavesUserStashWhenNothingStashed(t *testing.T) {
+	sub := initLocalRepo(t)
+	src := initLocalRepo(t)
+	run(t, src, "git", "-c", "protocol.file.allow=always", "submodule", "add", sub, "libs/sub")
+	run(t, src, "git", "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "--no-gpg-sign", "-m", "add submodule")
+	dest := cloneLocalRepo(t, src)
+	run(t, dest, "git", "-c", "protocol.file.allow=always", "submodule", "update", "--init")
+	run(t, dest, "git", "config", "user.email", "test@test.com")
+	run(t, dest, "git", "config", "user.name", "Test")
+
+	// An earlier stash of the user's own, then dirt that stash push cannot save
+	if err := os.WriteFile(filepath.Join(dest, "README.md"), []byte("mine"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	run(t, dest, "git", "stash", "push", "-m", "user stash")
+	if err := os.WriteFile(filepath.Join(dest, "libs", "sub", "untracked.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	commitFile(t, src, "remote.txt")
+
+	m := NewManager("")
+	repo := &types.Repository{Name: "test", Path: dest, URL: src, Branch: "master", Autostash: boolPtr(true)}
+
+	report, err := m.Update(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("expected update to succeed, got %v", err)
+	}
+	if report.Stashed || headCommit(t, dest) != headCommit(t, src) {
+		t.Errorf("expected unstashed fast-forward, got %+v", report)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dest, "README.md")); string(data) != "hello" {
+		t.Errorf("expected the user's stash not to be applied, README is %q", data)
+	}
+	if out := stashList(t, dest); !strings.Contains(out, "user stash") {
+		t.Errorf("expected the user's stash to be kept, got %q", out)
+	}
+}
+
 // --- Execute ---
 
 func TestExecute_Success(t *testing.T) {
@@ -403,6 +803,38 @@ func TestExecute_FailingCommand(t *testing.T) {
 	if result.Success {
 		t.Error("expected failure for invalid git subcommand")
 	}
+	if result.ExitCode <= 0 {
+		t.Errorf("expected a positive exit code, got %d", result.ExitCode)
+	}
+
+	result, _ = m.Execute(context.Background(), repo, "no-such-command-xyz")
+	if result.Success || result.ExitCode != -1 {
+		t.Errorf("expected exit code -1 for a command that cannot start, got %d", result.ExitCode)
+	}
+}
+
+func TestExecuteStream_PassesLines(t *testing.T) {
+	dir := initLocalRepo(t)
+	m := NewManager("")
+	repo := &types.Repository{Name: "test", Path: dir}
+
+	var lines []string
+	result, err := m.ExecuteStream(context.Background(), repo, func(line string) {
+		lines = append(lines, line)
+	}, "sh", "-c", "echo one; echo two >&2; exit 3")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	if len(lines) != 2 || lines[0] != "one" || lines[1] != "two" {
+		t.Errorf("expected stdout and stderr lines in order, got %q", lines)
+	}
+	if result.Output != "one\ntwo\n" {
+		t.Errorf("expected collected output, got %q", result.Output)
+	}
+	if result.Success || result.ExitCode != 3 {
+		t.Errorf("expected exit code 3, got %d (success %v)", result.ExitCode, result.Success)
+	}
 }
 
 // --- buildEnvironment ---
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// isMirror reports whether the repository is kept as a bare mirror clone
+func isMirror(repo *types.Repository) bool {
+	return repo.Mode == types.ModeMirror
+}
+
+// isBareRepository reports whether path holds a bare git repository
+func isBareRepository(path string) bool {
+	if _, err := os.Stat(filepath.Join(path, "HEAD")); err != nil {
+		return false
+	}
+	stat, err := os.Stat(filepath.Join(path, "objects"))
+	return err == nil && stat.IsDir()
+}
+
+// cloneMirror creates a bare mirror of every ref on the remote. Clone options,
+// submodules, LFS, worktrees and extra remotes only apply to checkouts.
+func (m *Manager) cloneMirror(ctx context.Context, repo *types.Repository, repoPath string) error {
+	types.ReportProgress(ctx, "mirroring")
+	if output, err := m.runGitIn(ctx, repo, "", "clone", "--mirror", repo.URL, repoPath); err != nil {
+		return newGitError("clone", err, output)
+	}
+	return nil
+}
+
+// updateMirror fetches every ref of the mirror's remote, removing refs that
+// were deleted there, and reports how many refs changed
+func (m *Manager) updateMirror(ctx context.Context, repo *types.Repository) (*types.UpdateReport, error) {
+	before, err := m.listRefs(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	types.ReportProgress(ctx, "fetching all refs")
+	if output, err := m.runGit(ctx, repo, "remote", "update", "--prune"); err != nil {
+		return nil, newGitError("remote update", err, output)
+	}
+
+	after, err := m.listRefs(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := 0
+	for ref, hash := range after {
+		if before[ref] != hash {
+			changed++
+		}
+	}
+	for ref := range before {
+		if _, ok := after[ref]; !ok {
+			changed++
+		}
+	}
+
+	report := &types.UpdateReport{Action: "mirror already up to date", Incoming: changed}
+	if changed > 0 {
+		report.Action = fmt.Sprintf("mirror updated, %d ref(s) changed", changed)
+	}
+	return report, nil
+}
+
+// mirrorStatus reports the mirror's default branch, ref counts and last fetch
+func (m *Manager) mirrorStatus(ctx context.Context, repo *types.Repository) (*types.RepoStatus, error) {
+	repoPath := m.getRepoPath(repo)
+	refs, err := m.listRefs(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	mirror := &types.MirrorStatus{Refs: len(refs)}
+	for ref := range refs {
+		switch {
+		case strings.HasPrefix(ref, "refs/heads/"):
+			mirror.Branches++
+		case strings.HasPrefix(ref, "refs/tags/"):
+			mirror.Tags++
+		}
+	}
+
+	// Fetches write FETCH_HEAD; a mirror that was never updated still has the
+	// packed-refs file written by the clone
+	for _, name := range []string{"FETCH_HEAD", "packed-refs"} {
+		if stat, err := os.Stat(filepath.Join(repoPath, name)); err == nil {
+			mirror.LastFetch = stat.ModTime()
+			break
+		}
+	}
+
+	status := &types.RepoStatus{Path: repoPath, IsClean: true, Mirror: mirror}
+	if output, err := m.runGit(ctx, repo, "symbolic-ref", "--short", "HEAD"); err == nil {
+		status.CurrentBranch = strings.TrimSpace(string(output))
+	}
+	return status, nil
+}
+
+// listRefs maps every ref in the repository to the object it points at
+func (m *Manager) listRefs(ctx context.Context, repo *types.Repository) (map[string]string, error) {
+	output, err := m.runGit(ctx, repo, "for-each-ref", "--format=%(objectname) %(refname)")
+	if err != nil {
+		return nil, newGitError("for-each-ref", err, output)
+	}
+
+	refs := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if hash, ref, ok := strings.Cut(line, " "); ok {
+			refs[ref] = hash
+		}
+	}
+	return refs, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+func TestMirror_CloneUpdateStatus(t *testing.T) {
+	src := initLocalRepo(t)
+	run(t, src, "git", "branch", "old")
+	ctx := context.Background()
+
+	m := NewManager("")
+	repo := &types.Repository{Name: "backup", Path: filepath.Join(t.TempDir(), "backup.git"), URL: src, Mode: types.ModeMirror}
+	if err := m.Clone(ctx, repo); err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+	if !m.Exists(repo) {
+		t.Fatal("expected the bare mirror to exist")
+	}
+	if gitOutput(t, repo.Path, "rev-parse", "--is-bare-repository") != "true" {
+		t.Error("expected a bare repository")
+	}
+
+	report, err := m.Update(ctx, repo)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got := report.String(); got != "mirror already up to date" {
+		t.Errorf("unexpected report %q", got)
+	}
+
+	commitFile(t, src, "new.txt")
+	run(t, src, "git", "branch", "-D", "old")
+	run(t, src, "git", "tag", "v1")
+
+	report, err = m.Update(ctx, repo)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got := report.String(); got != "mirror updated, 3 ref(s) changed" {
+		t.Errorf("unexpected report %q", got)
+	}
+
+	status, err := m.Status(ctx, repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.Mirror == nil {
+		t.Fatal("expected mirror status")
+	}
+	if status.Mirror.Branches != 1 || status.Mirror.Tags != 1 || status.Mirror.Refs != 2 {
+		t.Errorf("unexpected ref counts %+v", status.Mirror)
+	}
+	if status.Mirror.LastFetch.IsZero() {
+		t.Error("expected a last fetch time")
+	}
+	if status.CurrentBranch != "master" {
+		t.Errorf("expected HEAD on master, got %q", status.CurrentBranch)
+	}
+}
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// remoteNames returns the repository's configured remotes other than origin in
+// a stable order
+func remoteNames(repo *types.Repository) []string {
+	var names []string
+	for name := range repo.Remotes {
+		if name != "origin" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EnsureRemotes adds configured remotes missing from the clone and points
+// existing ones at their configured URL. Remotes that are not configured are
+// left alone. It returns a description of each change made.
+func (m *Manager) EnsureRemotes(ctx context.Context, repo *types.Repository) ([]string, error) {
+	var changes []string
+	for _, name := range remoteNames(repo) {
+		url := repo.Remotes[name]
+
+		current, err := m.runGit(ctx, repo, "remote", "get-url", name)
+		if err != nil {
+			if output, err := m.runGit(ctx, repo, "remote", "add", name, url); err != nil {
+				return changes, newGitError("remote add", err, output)
+			}
+			changes = append(changes, "added remote "+name)
+			continue
+		}
+
+		if strings.TrimSpace(string(current)) != url {
+			if output, err := m.runGit(ctx, repo, "remote", "set-url", name, url); err != nil {
+				return changes, newGitError("remote set-url", err, output)
+			}
+			changes = append(changes, "updated remote "+name)
+		}
+	}
+	return changes, nil
+}
+
+// OriginDrift returns origin's URL in the clone and whether it names a
+// different repository than the configured url, as after moving a repository
+// to another organization. SSH and HTTPS forms of the same repository match.
+// A clone without origin has drifted.
+func (m *Manager) OriginDrift(ctx context.Context, repo *types.Repository) (string, bool) {
+	current := m.originURL(ctx, repo)
+	return current, !SameRemote(current, repo.URL)
+}
+
+// FixOrigin points origin at the configured url, adding origin when the
+// clone has none
+func (m *Manager) FixOrigin(ctx context.Context, repo *types.Repository) error {
+	if m.originURL(ctx, repo) == "" {
+		if output, err := m.runGit(ctx, repo, "remote", "add", "origin", repo.URL); err != nil {
+			return newGitError("remote add", err, output)
+		}
+		return nil
+	}
+	if output, err := m.runGit(ctx, repo, "remote", "set-url", "origin", repo.URL); err != nil {
+		return newGitError("remote set-url", err, output)
+	}
+	return nil
+}
+
+// originURL reads origin's URL from the clone's configuration file, asking
+// git only when the file does not have it
+func (m *Manager) originURL(ctx context.Context, repo *types.Repository) string {
+	_, commonDir := gitDirs(m.getRepoPath(repo))
+	if isMirror(repo) {
+		commonDir = m.getRepoPath(repo)
+	}
+	if url, found := readConfigValue(filepath.Join(commonDir, "config"), "remote", "origin", "url"); found {
+		return url
+	}
+
+	output, err := m.gitOutput(ctx, repo, "remote", "get-url", "origin")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// fetchRemotes fetches origin and every configured remote
+func (m *Manager) fetchRemotes(ctx context.Context, repo *types.Repository) error {
+	args := append([]string{"fetch", "--multiple", "origin"}, remoteNames(repo)...)
+	if output, err := m.runGit(ctx, repo, args...); err != nil {
+		return newGitError("fetch", err, output)
+	}
+	return nil
+}
+
+// remoteStatus compares HEAD with branch on each configured remote, listing
+// origin first with the comparison Status already made. Repositories without
+// extra remotes return nothing; their origin comparison is already in
+// RepoStatus.AheadBehind.
+func (m *Manager) remoteStatus(ctx context.Context, repo *types.Repository, branch string, origin *types.BranchComparison) []types.RemoteStatus {
+	names := remoteNames(repo)
+	if len(names) == 0 {
+		return nil
+	}
+
+	remotes := make([]types.RemoteStatus, 0, len(names)+1)
+	for _, name := range append([]string{"origin"}, names...) {
+		if name == "origin" {
+			remotes = append(remotes, types.RemoteStatus{Name: name, URL: repo.URL, AheadBehind: origin})
+			continue
+		}
+		remotes = append(remotes, types.RemoteStatus{
+			Name:        name,
+			URL:         repo.Remotes[name],
+			AheadBehind: m.compareRefs(ctx, repo, "HEAD", name+"/"+branch),
+		})
+	}
+	return remotes
+}
+
+// compareRefs counts the commits only reachable from local (ahead) and only
+// reachable from remote (behind). It returns nil when either ref is missing.
+func (m *Manager) compareRefs(ctx context.Context, repo *types.Repository, local, remote string) *types.BranchComparison {
+	output, err := m.runGit(ctx, repo, "rev-list", "--count", "--left-right", local+"..."+remote)
+	if err != nil {
+		return nil
+	}
+
+	parts := strings.Split(strings.TrimSpace(string(output)), "\t")
+	if len(parts) != 2 {
+		return nil
+	}
+	comparison := &types.BranchComparison{}
+	fmt.Sscanf(parts[0], "%d", &comparison.Ahead)
+	fmt.Sscanf(parts[1], "%d", &comparison.Behind)
+	return comparison
+}
+
+// SyncFork fast-forwards the fork's tracked branch to the same branch on the
+// upstream remote, and pushes it to origin when push is set. The branch does
+// not need to be checked out; when it is, the working tree must be clean.
+// Forks with commits that upstream does not have are refused.
+func (m *Manager) SyncFork(ctx context.Context, repo *types.Repository, push bool) (*types.UpdateReport, error) {
+	if !m.Exists(repo) {
+		return nil, types.NewOperationError(types.ErrorNotFound, fmt.Errorf("repository does not exist at %s", m.getRepoPath(repo)))
+	}
+	if repo.Remotes[types.UpstreamRemote] == "" {
+		return nil, fmt.Errorf("no %s remote configured", types.UpstreamRemote)
+	}
+
+	if _, err := m.EnsureRemotes(ctx, repo); err != nil {
+		return nil, err
+	}
+	types.ReportProgress(ctx, "fetching")
+	if err := m.fetchRemotes(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	branch, _, err := m.ResolveBranch(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	upstream := types.UpstreamRemote + "/" + branch
+	local := "refs/heads/" + branch
+
+	comparison := m.compareRefs(ctx, repo, local, upstream)
+	if comparison == nil {
+		return nil, fmt.Errorf("cannot compare %s with %s; check that both branches exist", branch, upstream)
+	}
+
+	report := &types.UpdateReport{Strategy: types.StrategyFFOnly, Incoming: comparison.Behind, Local: comparison.Ahead}
+	if report.Local > 0 {
+		return nil, types.NewOperationError(types.ErrorDiverged,
+			fmt.Errorf("%s has %d commit(s) that are not in %s and cannot be fast-forwarded", branch, report.Local, upstream))
+	}
+
+	if report.Incoming == 0 {
+		report.Action = "already up to date with " + upstream
+	} else {
+		if err := m.fastForwardBranch(ctx, repo, branch, upstream); err != nil {
+			return nil, err
+		}
+		report.Action = fmt.Sprintf("fast-forwarded %d commit(s) from %s", report.Incoming, upstream)
+	}
+
+	if push {
+		if ab := m.compareRefs(ctx, repo, local, "origin/"+branch); ab == nil || ab.Ahead > 0 {
+			types.ReportProgress(ctx, "pushing to origin")
+			if output, err := m.runGit(ctx, repo, "push", "origin", local+":"+local); err != nil {
+				return nil, newGitError("push", err, output)
+			}
+			report.Action += ", pushed to origin"
+		}
+	}
+
+	return report, nil
+}
+
+// fastForwardBranch moves branch to target, which must contain it. A checked
+// out branch is merged so the working tree follows; any other branch only has
+// its ref moved.
+func (m *Manager) fastForwardBranch(ctx context.Context, repo *types.Repository, branch, target string) error {
+	types.ReportProgress(ctx, "fast-forwarding to "+target)
+
+	current, err := m.runGit(ctx, repo, "branch", "--show-current")
+	if err != nil {
+		return newGitError("branch", err, current)
+	}
+	if strings.TrimSpace(string(current)) == branch {
+		status, err := m.runGit(ctx, repo, "status", "--porcelain")
+		if err != nil {
+			return newGitError("status", err, status)
+		}
+		if strings.TrimSpace(string(status)) != "" {
+			return types.NewOperationError(types.ErrorDirty, fmt.Errorf("repository has uncommitted changes on %s, cannot fast-forward", branch))
+		}
+		if output, err := m.runGit(ctx, repo, "merge", "--ff-only", target); err != nil {
+			return newGitError("merge", err, output)
+		}
+		return nil
+	}
+
+	old, err := m.runGit(ctx, repo, "rev-parse", "refs/heads/"+branch)
+	if err != nil {
+		return newGitError("rev-parse", err, old)
+	}
+	if output, err := m.runGit(ctx, repo, "update-ref", "-m", "gorepos sync-forks", "refs/heads/"+branch, target, strings.TrimSpace(string(old))); err != nil {
+		return newGitError("update-ref", err, output)
+	}
+	return nil
+}
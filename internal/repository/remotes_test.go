@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// initFork creates an upstream repository and a bare fork of it, and returns
+// a fork repository configuration with an upstream remote.
+func initFork(t *testing.T) (upstream, fork string, repo *types.Repository) {
+	t.Helper()
+	upstream = initLocalRepo(t)
+	fork = filepath.Join(t.TempDir(), "fork.git")
+	run(t, "", "git", "clone", "--bare", upstream, fork)
+
+	repo = &types.Repository{
+		Name:    "fork",
+		Path:    filepath.Join(t.TempDir(), "fork"),
+		URL:     fork,
+		Branch:  "master",
+		Remotes: map[string]string{"upstream": upstream},
+	}
+	return upstream, fork, repo
+}
+
+// gitOutput runs git in dir and returns its trimmed output
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		t.Fatalf("git %v: %v", args, err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestRemotes_CloneStatusAndReconcile(t *testing.T) {
+	upstream, _, repo := initFork(t)
+	m := NewManager("")
+	ctx := context.Background()
+
+	if err := m.Clone(ctx, repo); err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+	if got := gitOutput(t, repo.Path, "remote", "get-url", "upstream"); got != upstream {
+		t.Errorf("expected upstream remote %s, got %s", upstream, got)
+	}
+
+	commitFile(t, upstream, "upstream.txt")
+	if _, err := m.Update(ctx, repo); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	status, err := m.Status(ctx, repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if len(status.Remotes) != 2 || status.Remotes[0].Name != "origin" || status.Remotes[1].Name != "upstream" {
+		t.Fatalf("expected origin and upstream remotes, got %+v", status.Remotes)
+	}
+	if ab := status.Remotes[1].AheadBehind; ab == nil || ab.Behind != 1 || ab.Ahead != 0 {
+		t.Errorf("expected 1 commit behind upstream, got %+v", ab)
+	}
+	if ab := status.Remotes[0].AheadBehind; ab == nil || ab.Behind != 0 {
+		t.Errorf("expected origin up to date, got %+v", ab)
+	}
+
+	moved := initLocalRepo(t)
+	repo.Remotes["upstream"] = moved
+	changes, err := m.EnsureRemotes(ctx, repo)
+	if err != nil {
+		t.Fatalf("ensure remotes: %v", err)
+	}
+	if len(changes) != 1 || gitOutput(t, repo.Path, "remote", "get-url", "upstream") != moved {
+		t.Errorf("expected upstream to be repointed, got changes %v", changes)
+	}
+}
+
+func TestSyncFork_FastForwardsAndPushes(t *testing.T) {
+	upstream, fork, repo := initFork(t)
+	m := NewManager("")
+	ctx := context.Background()
+
+	if err := m.Clone(ctx, repo); err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+	commitFile(t, upstream, "upstream.txt")
+
+	report, err := m.SyncFork(ctx, repo, true)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if got := report.String(); got != "ff-only: fast-forwarded 1 commit(s) from upstream/master, pushed to origin" {
+		t.Errorf("unexpected report %q", got)
+	}
+	if headCommit(t, repo.Path) != headCommit(t, upstream) {
+		t.Error("expected checked out branch to match upstream")
+	}
+	if gitOutput(t, fork, "rev-parse", "master") != strings.TrimSpace(headCommit(t, upstream)) {
+		t.Error("expected fork to be pushed to upstream's commit")
+	}
+
+	// A branch that is not checked out only has its ref moved
+	run(t, repo.Path, "git", "checkout", "-b", "feature")
+	commitFile(t, upstream, "second.txt")
+	if _, err := m.SyncFork(ctx, repo, false); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if gitOutput(t, repo.Path, "rev-parse", "master") != strings.TrimSpace(headCommit(t, upstream)) {
+		t.Error("expected master to be fast-forwarded while feature is checked out")
+	}
+	if gitOutput(t, repo.Path, "branch", "--show-current") != "feature" {
+		t.Error("expected feature to stay checked out")
+	}
+}
+
+func TestSyncFork_RefusesDivergedFork(t *testing.T) {
+	upstream, _, repo := initFork(t)
+	m := NewManager("")
+	ctx := context.Background()
+
+	if err := m.Clone(ctx, repo); err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+	commitFile(t, repo.Path, "fork-only.txt")
+	commitFile(t, upstream, "upstream.txt")
+
+	_, err := m.SyncFork(ctx, repo, false)
+	if types.CategoryOf(err) != types.ErrorDiverged {
+		t.Errorf("expected diverged error, got %v", err)
+	}
+
+	if _, err := m.SyncFork(ctx, &types.Repository{Name: "plain", Path: repo.Path, URL: repo.URL}, false); err == nil {
+		t.Error("expected an error for a repository without an upstream remote")
+	}
+}
+
+func TestOriginDrift_DetectAndFix(t *testing.T) {
+	src := initLocalRepo(t)
+	moved := initLocalRepo(t)
+	dest := cloneLocalRepo(t, src)
+	ctx := context.Background()
+
+	m := NewManager("")
+	repo := &types.Repository{Name: "test", Path: dest, URL: moved, Branch: "master"}
+
+	status, err := m.Status(ctx, repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !status.OriginMismatch || status.OriginURL != src {
+		t.Errorf("expected origin %s to be flagged, got %q mismatch=%v", src, status.OriginURL, status.OriginMismatch)
+	}
+
+	if err := m.FixOrigin(ctx, repo); err != nil {
+		t.Fatalf("fix origin: %v", err)
+	}
+	if current, drifted := m.OriginDrift(ctx, repo); drifted || current != moved {
+		t.Errorf("expected origin %s after fix, got %q drifted=%v", moved, current, drifted)
+	}
+
+	// The SSH form of the configured HTTPS URL is the same repository
+	run(t, dest, "git", "remote", "set-url", "origin", "git@github.com:org/app.git")
+	repo.URL = "https://github.com/org/app"
+	if _, drifted := m.OriginDrift(ctx, repo); drifted {
+		t.Error("expected SSH and HTTPS forms to match")
+	}
+
+	// A clone without origin gets one
+	run(t, dest, "git", "remote", "remove", "origin")
+	if current, drifted := m.OriginDrift(ctx, repo); !drifted || current != "" {
+		t.Errorf("expected a missing origin to be flagged, got %q drifted=%v", current, drifted)
+	}
+	if err := m.FixOrigin(ctx, repo); err != nil {
+		t.Fatalf("fix origin: %v", err)
+	}
+	if got := gitOutput(t, dest, "remote", "get-url", "origin"); got != repo.URL {
+		t.Errorf("expected origin to be added as %s, got %q", repo.URL, got)
+	}
+}
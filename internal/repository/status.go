@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// StatusOptions control what Status gathers beyond git status itself
+type StatusOptions struct {
+	CommitDetails bool // Look up the last commit's author and date, one more git call per repository
+}
+
+// statusArgs requests machine-readable status with branch and stash headers.
+// -z keeps paths with spaces or special characters intact.
+var statusArgs = []string{"status", "--porcelain=v2", "--branch", "--show-stash", "-z"}
+
+// workingTreeStatus is the parsed output of git status --porcelain=v2
+type workingTreeStatus struct {
+	head       string // Commit hash, empty before the first commit
+	branch     string // Checked out branch, empty when detached
+	detached   bool
+	upstream   string
+	ahead      int
+	behind     int
+	hasCounts  bool // branch.ab was reported; git omits it when the upstream is gone
+	stashes    int
+	staged     []string
+	unstaged   []string
+	untracked  []string
+	conflicted []string
+}
+
+// parseStatusV2 parses NUL-separated git status --porcelain=v2 --branch
+// --show-stash output
+func parseStatusV2(output string) workingTreeStatus {
+	var status workingTreeStatus
+	records := strings.Split(output, "\x00")
+
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if record == "" {
+			continue
+		}
+
+		switch record[0] {
+		case '#':
+			key, value, _ := strings.Cut(strings.TrimPrefix(record, "# "), " ")
+			switch key {
+			case "branch.oid":
+				if value != "(initial)" {
+					status.head = value
+				}
+			case "branch.head":
+				if value == "(detached)" {
+					status.detached = true
+				} else {
+					status.branch = value
+				}
+			case "branch.upstream":
+				status.upstream = value
+			case "branch.ab":
+				// +ahead -behind relative to the upstream
+				if ahead, behind, ok := strings.Cut(value, " "); ok {
+					status.ahead, _ = strconv.Atoi(strings.TrimPrefix(ahead, "+"))
+					status.behind, _ = strconv.Atoi(strings.TrimPrefix(behind, "-"))
+					status.hasCounts = true
+				}
+			case "stash":
+				status.stashes, _ = strconv.Atoi(value)
+			}
+		case '1':
+			// 1 XY sub mH mI mW hH hI path
+			if fields := strings.SplitN(record, " ", 9); len(fields) == 9 {
+				status.addChange(fields[1], fields[8])
+			}
+		case '2':
+			// 2 XY sub mH mI mW hH hI Xscore path, followed by the original path
+			if fields := strings.SplitN(record, " ", 10); len(fields) == 10 {
+				status.addChange(fields[1], fields[9])
+			}
+			i++
+		case 'u':
+			// u XY sub m1 m2 m3 mW h1 h2 h3 path
+			if fields := strings.SplitN(record, " ", 11); len(fields) == 11 {
+				status.conflicted = append(status.conflicted, fields[10])
+			}
+		case '?':
+			status.untracked = append(status.untracked, strings.TrimPrefix(record, "? "))
+		}
+	}
+	return status
+}
+
+// addChange files a changed path under staged and/or unstaged from its XY code
+func (s *workingTreeStatus) addChange(xy, path string) {
+	if len(xy) != 2 {
+		return
+	}
+	if xy[0] != '.' {
+		s.staged = append(s.staged, path)
+	}
+	if xy[1] != '.' {
+		s.unstaged = append(s.unstaged, path)
+	}
+}
+
+// changedPaths returns every changed path once, in status order
+func (s workingTreeStatus) changedPaths() []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, list := range [][]string{s.conflicted, s.staged, s.unstaged, s.untracked} {
+		for _, path := range list {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// compareUpstream returns the ahead/behind counts git status reported when the
+// checked out branch's upstream is remoteRef, sparing a rev-list call
+func (s workingTreeStatus) compareUpstream(remoteRef string) (*types.BranchComparison, bool) {
+	if !s.hasCounts || s.detached || s.upstream != remoteRef {
+		return nil, false
+	}
+	return &types.BranchComparison{Ahead: s.ahead, Behind: s.behind}, true
+}
+
+// lastCommit returns the hash, author and date of HEAD. When git log fails
+// only the hash is known.
+func (m *Manager) lastCommit(ctx context.Context, repo *types.Repository, hash string) *types.CommitInfo {
+	output, err := m.gitOutput(ctx, repo, "log", "-1", "--format=%an%x00%aI", hash)
+	if err != nil {
+		return &types.CommitInfo{Hash: hash}
+	}
+
+	author, date, _ := strings.Cut(strings.TrimSpace(string(output)), "\x00")
+	commit := &types.CommitInfo{Hash: hash, Author: author}
+	commit.Date, _ = time.Parse(time.RFC3339, date)
+	return commit
+}
+
+// gitDirs returns the git directory of a checkout and the common directory
+// shared by its worktrees. Worktrees and submodules have a .git file naming
+// their git directory, which names the common directory in its commondir file.
+func gitDirs(path string) (gitDir, commonDir string) {
+	gitDir = filepath.Join(path, ".git")
+	if data, err := os.ReadFile(gitDir); err == nil {
+		if dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:"); ok {
+			gitDir = resolveRelative(path, strings.TrimSpace(dir))
+		}
+	}
+
+	commonDir = gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = resolveRelative(gitDir, strings.TrimSpace(string(data)))
+	}
+	return gitDir, commonDir
+}
+
+// resolveRelative resolves path against base unless it is absolute
+func resolveRelative(base, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
+
+// isShallow reports whether the clone has truncated history, which git
+// records in the shallow file of the common directory
+func (m *Manager) isShallow(repo *types.Repository) bool {
+	_, commonDir := gitDirs(m.getRepoPath(repo))
+	_, err := os.Stat(filepath.Join(commonDir, "shallow"))
+	return err == nil
+}
+
+// isSparse reports whether core.sparseCheckout is enabled in the repository
+// or worktree configuration, reading the files instead of running git config
+func (m *Manager) isSparse(repo *types.Repository) bool {
+	gitDir, commonDir := gitDirs(m.getRepoPath(repo))
+	sparse := false
+	for _, file := range []string{filepath.Join(commonDir, "config"), filepath.Join(gitDir, "config.worktree")} {
+		if value, ok := readConfigBool(file, "core", "sparsecheckout"); ok {
+			sparse = value
+		}
+	}
+	return sparse
+}
+
+// readConfigBool reads the last value of a boolean key from a git config
+// file. A key without a value is true.
+func readConfigBool(file, section, key string) (value, found bool) {
+	raw, found := readConfigValue(file, section, "", key)
+	if !found {
+		return false, false
+	}
+	switch strings.ToLower(raw) {
+	case "true", "yes", "on", "1":
+		return true, true
+	}
+	return false, true
+}
+
+// readConfigValue reads the last value of a key from a git config file, in
+// [section] or [section "subsection"]. Section and key names are
+// case-insensitive, subsections are not. A key without a value reads as
+// "true". Includes are not followed.
+func readConfigValue(file, section, subsection, key string) (value string, found bool) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	inSection := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			name, sub, _ := strings.Cut(strings.Trim(line, "[]"), " ")
+			inSection = strings.EqualFold(name, section) && strings.Trim(sub, `"`) == subsection
+			continue
+		}
+		if !inSection || line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		name, raw, hasValue := strings.Cut(line, "=")
+		if !strings.EqualFold(strings.TrimSpace(name), key) {
+			continue
+		}
+		found = true
+		value = "true"
+		if hasValue {
+			value = strings.Trim(strings.TrimSpace(raw), `"`)
+		}
+	}
+	return value, found
+}
+
+// EnableStatusCache makes Status remember each repository's status for the
+// rest of the run, so commands that show a repository in several places read
+// it once. Only enable it for runs that do not modify repositories; Update
+// always reads fresh status.
+func (m *Manager) EnableStatusCache() {
+	m.statusCache = &statusCache{entries: make(map[string]*statusEntry)}
+}
+
+// statusCache holds statuses by repository path. Failed reads are not kept,
+// so a later call tries again.
+type statusCache struct {
+	mu      sync.Mutex
+	entries map[string]*statusEntry
+}
+
+// statusEntry serializes reads of one repository so concurrent callers share
+// a single git call
+type statusEntry struct {
+	mu     sync.Mutex
+	status *types.RepoStatus
+}
+
+// get returns the cached status for path, calling read on a miss
+func (c *statusCache) get(path string, read func() (*types.RepoStatus, error)) (*types.RepoStatus, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[path]
+	if !ok {
+		entry = &statusEntry{}
+		c.entries[path] = entry
+	}
+	c.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.status != nil {
+		return entry.status, nil
+	}
+	status, err := read()
+	if err != nil {
+		return nil, err
+	}
+	entry.status = status
+	return status, nil
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+func TestParseStatusV2(t *testing.T) {
+	records := []string{
+		"# branch.oid 1111111111111111111111111111111111111111",
+		"# branch.head main",
+		"# branch.upstream origin/main",
+		"# branch.ab +1 -2",
+		"# stash 3",
+		"1 M. N... 100644 100644 100644 aaaa bbbb staged file.txt",
+		"1 .M N... 100644 100644 100644 aaaa aaaa unstaged file.txt",
+		"1 MM N... 100644 100644 100644 aaaa bbbb both.txt",
+		"2 R. N... 100644 100644 100644 aaaa aaaa R100 new name.txt",
+		"old name.txt",
+		"u UU N... 100644 100644 100644 100644 aaaa bbbb cccc conflict file.txt",
+		"? untracked file.txt",
+	}
+	got := parseStatusV2(strings.Join(records, "\x00") + "\x00")
+
+	if got.head != "1111111111111111111111111111111111111111" || got.branch != "main" || got.detached {
+		t.Errorf("unexpected head %q branch %q detached %v", got.head, got.branch, got.detached)
+	}
+	if got.upstream != "origin/main" || got.stashes != 3 {
+		t.Errorf("unexpected upstream %q stashes %d", got.upstream, got.stashes)
+	}
+	if ab, ok := got.compareUpstream("origin/main"); !ok || ab.Ahead != 1 || ab.Behind != 2 {
+		t.Errorf("expected 1 ahead, 2 behind from branch.ab, got %+v", ab)
+	}
+	if _, ok := got.compareUpstream("origin/develop"); ok {
+		t.Error("expected no counts for another upstream")
+	}
+
+	checks := map[string]struct{ got, want []string }{
+		"staged":     {got.staged, []string{"staged file.txt", "both.txt", "new name.txt"}},
+		"unstaged":   {got.unstaged, []string{"unstaged file.txt", "both.txt"}},
+		"untracked":  {got.untracked, []string{"untracked file.txt"}},
+		"conflicted": {got.conflicted, []string{"conflict file.txt"}},
+	}
+	for name, c := range checks {
+		if !reflect.DeepEqual(c.got, c.want) {
+			t.Errorf("%s: got %q, want %q", name, c.got, c.want)
+		}
+	}
+	if paths := got.changedPaths(); len(paths) != 6 {
+		t.Errorf("expected 6 distinct changed paths, got %q", paths)
+	}
+
+	detached := parseStatusV2("# branch.oid (initial)\x00# branch.head (detached)\x00")
+	if !detached.detached || detached.branch != "" || detached.head != "" {
+		t.Errorf("unexpected detached status %+v", detached)
+	}
+}
+
+func TestStatus_ReportsWorkingTreeDetails(t *testing.T) {
+	src := initLocalRepo(t)
+	dest := cloneLocalRepo(t, src)
+	run(t, dest, "git", "config", "user.email", "test@test.com")
+	run(t, dest, "git", "config", "user.name", "Test")
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dest, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	write("stashed.txt", "stash me")
+	run(t, dest, "git", "stash", "push", "--include-untracked")
+	write("README.md", "changed")
+	write("staged file.txt", "staged")
+	run(t, dest, "git", "add", "staged file.txt")
+	write("new file.txt", "untracked")
+
+	m := NewManager("")
+	m.SetStatusOptions(StatusOptions{CommitDetails: true})
+	repo := &types.Repository{Name: "test", Path: dest, URL: src, Branch: "master"}
+	status, err := m.Status(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+
+	if !reflect.DeepEqual(status.Staged, []string{"staged file.txt"}) ||
+		!reflect.DeepEqual(status.Unstaged, []string{"README.md"}) ||
+		!reflect.DeepEqual(status.Untracked, []string{"new file.txt"}) {
+		t.Errorf("unexpected lists: staged %q unstaged %q untracked %q", status.Staged, status.Unstaged, status.Untracked)
+	}
+	if status.IsClean || len(status.UncommittedFiles) != 3 {
+		t.Errorf("expected 3 uncommitted files, got %q", status.UncommittedFiles)
+	}
+	if status.StashCount != 1 {
+		t.Errorf("expected 1 stash, got %d", status.StashCount)
+	}
+	if status.Upstream != "origin/master" || status.Detached {
+		t.Errorf("unexpected upstream %q detached %v", status.Upstream, status.Detached)
+	}
+	if c := status.LastCommit; c == nil || c.Hash != strings.TrimSpace(headCommit(t, dest)) || c.Author != "Test" || c.Date.IsZero() {
+		t.Errorf("unexpected last commit %+v", c)
+	}
+
+	run(t, dest, "git", "checkout", "--quiet", "--detach")
+	status, err = m.Status(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !status.Detached || status.CurrentBranch != "" {
+		t.Errorf("expected detached HEAD, got branch %q detached %v", status.CurrentBranch, status.Detached)
+	}
+}
+
+func TestStatus_AheadBehindFromStatus(t *testing.T) {
+	src := initLocalRepo(t)
+	dest := cloneLocalRepo(t, src)
+	commitFile(t, dest, "local.txt")
+	commitFile(t, src, "remote.txt")
+	run(t, dest, "git", "fetch", "--quiet", "origin")
+
+	m := NewManager("")
+	repo := &types.Repository{Name: "test", Path: dest, URL: src, Branch: "master"}
+	status, err := m.Status(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if ab := status.AheadBehind; ab == nil || ab.Ahead != 1 || ab.Behind != 1 {
+		t.Errorf("expected 1 ahead, 1 behind, got %+v", ab)
+	}
+	if c := status.LastCommit; c == nil || c.Hash == "" || c.Author != "" {
+		t.Errorf("expected the commit hash without details, got %+v", c)
+	}
+
+	// Another branch checked out is still compared with the tracked branch
+	run(t, dest, "git", "checkout", "--quiet", "-b", "topic", "origin/master")
+	status, err = m.Status(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if ab := status.AheadBehind; ab == nil || ab.Ahead != 0 || ab.Behind != 0 {
+		t.Errorf("expected topic to match origin/master, got %+v", ab)
+	}
+}
+
+func TestStatus_CacheReadsOncePerRepository(t *testing.T) {
+	dir := initLocalRepo(t)
+	m := NewManager("")
+	m.EnableStatusCache()
+	repo := &types.Repository{Name: "test", Path: dir, Branch: "master"}
+	calls := countGitCalls(t)
+
+	first, err := m.Status(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	read := calls()
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	second, err := m.Status(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if second != first || !second.IsClean {
+		t.Error("expected the cached status to be returned")
+	}
+	if got := calls(); !reflect.DeepEqual(got, read) {
+		t.Errorf("expected no git calls for the cached status, got %v after %v", got[len(read):], read)
+	}
+
+	if _, err := m.Status(context.Background(), &types.Repository{Name: "missing", Path: filepath.Join(dir, "missing")}); err == nil {
+		t.Error("expected an error for a missing repository")
+	}
+}
+
+func TestReadConfigBool(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config")
+	content := "[core]\n\tbare = false\n[Core]\n\tSparseCheckout = true\n[extensions]\n\tsparseCheckout = false\n[core]\n\tflag\n" +
+		"[remote \"origin\"]\n\turl = git@github.com:org/repo.git\n[remote \"upstream\"]\n\turl = https://github.com/up/repo.git\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, found := readConfigBool(file, "core", "sparsecheckout"); !found || !value {
+		t.Errorf("expected core.sparseCheckout true, got %v (found %v)", value, found)
+	}
+	if value, found := readConfigBool(file, "core", "flag"); !found || !value {
+		t.Errorf("expected a bare key to be true, got %v (found %v)", value, found)
+	}
+	if _, found := readConfigBool(file, "core", "missing"); found {
+		t.Error("expected a missing key not to be found")
+	}
+	if url, _ := readConfigValue(file, "remote", "origin", "url"); url != "git@github.com:org/repo.git" {
+		t.Errorf("expected origin's url, got %q", url)
+	}
+}
+
+func TestGitDirs_Worktree(t *testing.T) {
+	dir := initLocalRepo(t)
+	wt := filepath.Join(t.TempDir(), "wt")
+	run(t, dir, "git", "worktree", "add", "--quiet", "-b", "topic", wt)
+
+	gitDir, commonDir := gitDirs(wt)
+	if !samePath(commonDir, filepath.Join(dir, ".git")) {
+		t.Errorf("expected common dir %s, got %s", filepath.Join(dir, ".git"), commonDir)
+	}
+	if !samePath(filepath.Dir(gitDir), filepath.Join(dir, ".git", "worktrees")) {
+		t.Errorf("expected git dir under .git/worktrees, got %s", gitDir)
+	}
+}
+
+// countGitCalls puts a git wrapper first on PATH that records each call's
+// subcommand, and returns a function listing the calls made so far
+func countGitCalls(tb testing.TB) func() []string {
+	tb.Helper()
+	real, err := exec.LookPath("git")
+	if err != nil {
+		tb.Fatalf("git not found: %v", err)
+	}
+	dir := tb.TempDir()
+	log := filepath.Join(dir, "calls")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$1\" >> %q\nexec %q \"$@\"\n", log, real)
+	if err := os.WriteFile(filepath.Join(dir, "git"), []byte(script), 0755); err != nil {
+		tb.Fatal(err)
+	}
+	tb.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return func() []string {
+		data, _ := os.ReadFile(log)
+		return strings.Fields(string(data))
+	}
+}
+
+// BenchmarkStatus measures one repository's status with a few changes, which
+// should cost a single git status process
+func BenchmarkStatus(b *testing.B) {
+	src := initLocalRepo(b)
+	dest := cloneLocalRepo(b, src)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dest, name), []byte(name), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	m := NewManager("")
+	repo := &types.Repository{Name: "bench", Path: dest, URL: src, Branch: "master"}
+	ctx := context.Background()
+	calls := countGitCalls(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := m.Status(ctx, repo); err != nil {
+			b.Fatalf("status: %v", err)
+		}
+	}
+	b.StopTimer()
+
+	counts := make(map[string]int)
+	for _, call := range calls() {
+		counts[call]++
+	}
+	if want := map[string]int{"status": b.N}; !reflect.DeepEqual(counts, want) {
+		b.Errorf("expected one git status per read, got %v over %d reads", counts, b.N)
+	}
+}
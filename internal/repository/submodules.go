@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// updateSubmodules initializes and updates submodules to their recorded
+// commits when the repository asks for recursive submodules
+func (m *Manager) updateSubmodules(ctx context.Context, repo *types.Repository) (bool, error) {
+	if repo.Submodules != types.SubmodulesRecursive {
+		return false, nil
+	}
+
+	types.ReportProgress(ctx, "updating submodules")
+	if output, err := m.runGit(ctx, repo, "submodule", "sync", "--recursive"); err != nil {
+		return false, newGitError("submodule sync", err, output)
+	}
+	if output, err := m.runGit(ctx, repo, "submodule", "update", "--init", "--recursive"); err != nil {
+		return false, newGitError("submodule update", err, output)
+	}
+	return true, nil
+}
+
+// pullLFS downloads Git LFS objects for the checked out commit and replaces
+// pointer files with their content when the repository enables LFS
+func (m *Manager) pullLFS(ctx context.Context, repo *types.Repository) (bool, error) {
+	if !repo.LFS {
+		return false, nil
+	}
+
+	types.ReportProgress(ctx, "fetching LFS objects")
+	for _, args := range [][]string{{"lfs", "install", "--local"}, {"lfs", "fetch"}, {"lfs", "checkout"}} {
+		if output, err := m.runGit(ctx, repo, args...); err != nil {
+			if lfsMissing(output) {
+				return false, fmt.Errorf("repository %s sets lfs: true but git-lfs is not installed", repo.Name)
+			}
+			return false, newGitError(strings.Join(args, " "), err, output)
+		}
+	}
+	return true, nil
+}
+
+// lfsMissing reports whether git output says the lfs subcommand does not exist
+func lfsMissing(output []byte) bool {
+	return strings.Contains(string(output), "'lfs' is not a git command")
+}
+
+// submoduleStatus lists submodules that are not checked out at the commit
+// recorded by the parent repository. Repositories without a .gitmodules file
+// are skipped without running git.
+func (m *Manager) submoduleStatus(ctx context.Context, repo *types.Repository) []types.SubmoduleStatus {
+	if _, err := os.Stat(filepath.Join(m.getRepoPath(repo), ".gitmodules")); err != nil {
+		return nil
+	}
+	output, err := m.runGit(ctx, repo, "submodule", "status", "--recursive")
+	if err != nil {
+		return nil
+	}
+	return parseSubmoduleStatus(string(output))
+}
+
+// parseSubmoduleStatus parses git submodule status output, where a leading
+// '-' marks an uninitialized submodule, '+' one at another commit and 'U' one
+// with conflicts
+func parseSubmoduleStatus(output string) []types.SubmoduleStatus {
+	var submodules []types.SubmoduleStatus
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		fields := strings.Fields(line[1:])
+		if len(fields) < 2 {
+			continue
+		}
+
+		var state types.SubmoduleState
+		switch line[0] {
+		case '-':
+			state = types.SubmoduleUninitialized
+		case '+':
+			state = types.SubmoduleOutOfSync
+		case 'U':
+			state = types.SubmoduleConflict
+		default:
+			continue
+		}
+		submodules = append(submodules, types.SubmoduleStatus{Path: fields[1], Commit: fields[0], State: state})
+	}
+	return submodules
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// allowFileSubmodules lets git clone submodules from local paths, which it
+// refuses by default
+var allowFileSubmodules = map[string]string{
+	"GIT_CONFIG_COUNT":   "1",
+	"GIT_CONFIG_KEY_0":   "protocol.file.allow",
+	"GIT_CONFIG_VALUE_0": "always",
+}
+
+func TestParseSubmoduleStatus(t *testing.T) {
+	output := " 1111111 libs/ok (heads/main)\n" +
+		"-2222222 libs/missing\n" +
+		"+3333333 libs/moved (v1.0-1-g3333333)\n" +
+		"U4444444 libs/conflict\n"
+
+	got := parseSubmoduleStatus(output)
+	want := []types.SubmoduleStatus{
+		{Path: "libs/missing", Commit: "2222222", State: types.SubmoduleUninitialized},
+		{Path: "libs/moved", Commit: "3333333", State: types.SubmoduleOutOfSync},
+		{Path: "libs/conflict", Commit: "4444444", State: types.SubmoduleConflict},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d submodules, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("submodule %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestLFSMissing(t *testing.T) {
+	if !lfsMissing([]byte("git: 'lfs' is not a git command. See 'git --help'.")) {
+		t.Error("expected missing git-lfs to be recognized")
+	}
+	if lfsMissing([]byte("batch response: Repository not found")) {
+		t.Error("expected other lfs errors not to be reported as missing git-lfs")
+	}
+}
+
+func TestCloneAndUpdate_RecursiveSubmodules(t *testing.T) {
+	sub := initLocalRepo(t)
+	parent := initLocalRepo(t)
+	run(t, parent, "git", "-c", "protocol.file.allow=always", "submodule", "add", sub, "libs/sub")
+	run(t, parent, "git", "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "--no-gpg-sign", "-m", "add submodule")
+
+	dest := filepath.Join(t.TempDir(), "clone")
+	m := NewManager("")
+	repo := &types.Repository{
+		Name:        "test",
+		Path:        dest,
+		URL:         parent,
+		Submodules:  types.SubmodulesRecursive,
+		Environment: allowFileSubmodules,
+	}
+	if err := m.Clone(context.Background(), repo); err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "libs", "sub", "README.md")); err != nil {
+		t.Fatalf("expected submodule to be checked out: %v", err)
+	}
+
+	// Move the submodule forward in the parent and update the clone
+	commitFile(t, sub, "next.txt")
+	run(t, filepath.Join(parent, "libs", "sub"), "git", "-c", "protocol.file.allow=always", "pull", "--quiet", "origin", "HEAD")
+	run(t, parent, "git", "add", "libs/sub")
+	run(t, parent, "git", "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "--no-gpg-sign", "-m", "bump submodule")
+
+	report, err := m.Update(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if report.Action != "fast-forwarded 1 commit(s), updated submodules" {
+		t.Errorf("unexpected action %q", report.Action)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "libs", "sub", "next.txt")); err != nil {
+		t.Errorf("expected submodule to follow the recorded commit: %v", err)
+	}
+
+	status, err := m.Status(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if len(status.Submodules) != 0 {
+		t.Errorf("expected submodules in sync, got %+v", status.Submodules)
+	}
+
+	// Checking out another commit inside the submodule puts it out of sync
+	run(t, filepath.Join(dest, "libs", "sub"), "git", "checkout", "--quiet", "HEAD~1")
+	status, err = m.Status(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if len(status.Submodules) != 1 || status.Submodules[0].State != types.SubmoduleOutOfSync {
+		t.Errorf("expected one out of sync submodule, got %+v", status.Submodules)
+	}
+}
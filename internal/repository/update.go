@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// Update fetches the remote and integrates the configured branch using the
+// repository's update strategy (ff-only by default). Updates that would
+// discard local work are refused unless Force is set. Mirror clones fetch
+// every ref instead.
+func (m *Manager) Update(ctx context.Context, repo *types.Repository) (*types.UpdateReport, error) {
+	if !m.Exists(repo) {
+		return nil, types.NewOperationError(types.ErrorNotFound, fmt.Errorf("repository does not exist at %s", m.getRepoPath(repo)))
+	}
+	if isMirror(repo) {
+		return m.updateMirror(ctx, repo)
+	}
+
+	remoteChanges, err := m.EnsureRemotes(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch latest changes. A plain fetch keeps shallow, partial, single-branch
+	// and sparse clones as they are; re-applying --depth here would cut the new
+	// commits off from local history and make every update look diverged.
+	types.ReportProgress(ctx, "fetching")
+	if err := m.fetchRemotes(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	status, err := m.readStatus(ctx, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check repository status: %w", err)
+	}
+
+	if !status.IsClean {
+		if !m.updateOptions.Autostash && !repo.AutostashEnabled() {
+			return nil, types.NewOperationError(types.ErrorDirty, fmt.Errorf("repository has uncommitted changes, cannot update (use --autostash to stash them)"))
+		}
+	}
+
+	var report *types.UpdateReport
+	if status.IsClean {
+		report, err = m.integrate(ctx, repo, status)
+	} else {
+		report, err = m.updateWithStash(ctx, repo)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range remoteChanges {
+		report.Action += ", " + change
+	}
+	return report, nil
+}
+
+// integrate brings the remote branch into a clean working tree, then brings
+// submodules and LFS objects in line with the new commit
+func (m *Manager) integrate(ctx context.Context, repo *types.Repository, status *types.RepoStatus) (*types.UpdateReport, error) {
+	report, err := m.integrateBranch(ctx, repo, status)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := m.updateSubmodules(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	if updated {
+		report.Action += ", updated submodules"
+	}
+
+	pulled, err := m.pullLFS(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	if pulled {
+		report.Action += ", checked out LFS objects"
+	}
+	return report, nil
+}
+
+// integrateBranch moves the local branch onto the remote branch using the
+// repository's update strategy
+func (m *Manager) integrateBranch(ctx context.Context, repo *types.Repository, status *types.RepoStatus) (*types.UpdateReport, error) {
+	targetBranch, _, err := m.ResolveBranch(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	upstream := "origin/" + targetBranch
+
+	report := &types.UpdateReport{Strategy: effectiveStrategy(repo)}
+	if ab := status.AheadBehind; ab != nil {
+		report.Incoming = ab.Behind
+		report.Local = ab.Ahead
+	}
+
+	if status.CurrentBranch != targetBranch {
+		if !m.updateOptions.Force {
+			current := status.CurrentBranch
+			if current == "" {
+				current = "a detached HEAD"
+			}
+			return nil, types.NewOperationError(types.ErrorWrongBranch,
+				fmt.Errorf("HEAD is on %s, not %s; refusing to update (use --force to switch)", current, targetBranch))
+		}
+
+		types.ReportProgress(ctx, "switching to "+targetBranch)
+		if output, err := m.runGit(ctx, repo, "checkout", "-B", targetBranch, upstream); err != nil {
+			return nil, newGitError("checkout", err, output)
+		}
+		report.Action = fmt.Sprintf("switched from %s and reset to %s", status.CurrentBranch, upstream)
+		return report, nil
+	}
+
+	// The reset strategy still has work to do when only local commits exist
+	if report.Incoming == 0 && (report.Local == 0 || report.Strategy != types.StrategyReset) {
+		report.Action = "already up to date"
+		if report.Local > 0 {
+			report.Action = fmt.Sprintf("already up to date, %d local commit(s) not pushed", report.Local)
+		}
+		return report, nil
+	}
+
+	switch report.Strategy {
+	case types.StrategyRebase:
+		err = m.rebase(ctx, repo, upstream, report)
+	case types.StrategyMerge:
+		err = m.merge(ctx, repo, upstream, report)
+	case types.StrategyReset:
+		err = m.reset(ctx, repo, upstream, report)
+	default:
+		err = m.fastForward(ctx, repo, upstream, report)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// updateWithStash stashes local changes, updates, and re-applies them. When
+// they no longer apply cleanly the tree is reset to the updated branch and the
+// changes stay in the stash. Some dirt, such as untracked files inside a
+// submodule, cannot be stashed; the update then runs without a stash and
+// existing stash entries are left alone.
+func (m *Manager) updateWithStash(ctx context.Context, repo *types.Repository) (*types.UpdateReport, error) {
+	before, err := m.stashTop(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	types.ReportProgress(ctx, "stashing local changes")
+	if output, err := m.runGit(ctx, repo, "stash", "push", "--include-untracked", "-m", autostashMessage); err != nil {
+		return nil, newGitError("stash", err, output)
+	}
+	after, err := m.stashTop(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	report, updateErr := func() (*types.UpdateReport, error) {
+		status, err := m.readStatus(ctx, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check repository status: %w", err)
+		}
+		return m.integrate(ctx, repo, status)
+	}()
+
+	// Nothing was stashed, so stash@{0} is not ours to apply or drop
+	if after == before {
+		return report, updateErr
+	}
+	stashRef := fmt.Sprintf("stash@{0} (%.7s)", after)
+
+	types.ReportProgress(ctx, "re-applying stashed changes")
+	if output, err := m.runGit(ctx, repo, "stash", "apply", "stash@{0}"); err != nil {
+		// Leave a clean tree; the changes are still in the stash
+		m.runGit(ctx, repo, "reset", "--hard", "--quiet")
+		var outcome string
+		if updateErr == nil {
+			outcome = "update (" + report.String() + ")"
+		} else {
+			outcome = "failed update (" + updateErr.Error() + ")"
+		}
+		return nil, types.NewOperationError(types.ErrorConflict,
+			fmt.Errorf("local changes conflict after %s and were left in %s; resolve with 'git stash pop': %s", outcome, stashRef, strings.TrimSpace(string(output))))
+	}
+	if output, err := m.runGit(ctx, repo, "stash", "drop", "--quiet", "stash@{0}"); err != nil {
+		return nil, newGitError("stash drop", err, output)
+	}
+
+	if updateErr != nil {
+		return nil, updateErr
+	}
+	report.Stashed = true
+	report.Action += ", re-applied stashed changes"
+	return report, nil
+}
+
+// autostashMessage labels stashes created by updateWithStash
+const autostashMessage = "gorepos autostash"
+
+// stashTop returns the commit at the top of the stash, or "" when the stash
+// is empty
+func (m *Manager) stashTop(ctx context.Context, repo *types.Repository) (string, error) {
+	output, err := m.runGit(ctx, repo, "rev-parse", "--verify", "--quiet", "refs/stash")
+	if err != nil {
+		if len(output) == 0 {
+			return "", nil
+		}
+		return "", newGitError("rev-parse", err, output)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// effectiveStrategy returns the repository's update strategy, defaulting to ff-only
+func effectiveStrategy(repo *types.Repository) types.UpdateStrategy {
+	if repo.UpdateStrategy == "" {
+		return types.StrategyFFOnly
+	}
+	return repo.UpdateStrategy
+}
+
+// fastForward moves the branch to upstream when it has no local commits
+func (m *Manager) fastForward(ctx context.Context, repo *types.Repository, upstream string, report *types.UpdateReport) error {
+	if report.Local > 0 {
+		if m.updateOptions.Force {
+			return m.reset(ctx, repo, upstream, report)
+		}
+		return types.NewOperationError(types.ErrorDiverged,
+			fmt.Errorf("branch has diverged from %s (%d local, %d remote commits) and cannot be fast-forwarded; use the rebase or merge strategy, or --force to reset", upstream, report.Local, report.Incoming))
+	}
+
+	types.ReportProgress(ctx, "fast-forwarding to "+upstream)
+	if output, err := m.runGit(ctx, repo, "merge", "--ff-only", upstream); err != nil {
+		return newGitError("merge", err, output)
+	}
+	report.Action = fmt.Sprintf("fast-forwarded %d commit(s)", report.Incoming)
+	return nil
+}
+
+// rebase replays local commits on top of upstream, aborting on conflicts
+func (m *Manager) rebase(ctx context.Context, repo *types.Repository, upstream string, report *types.UpdateReport) error {
+	types.ReportProgress(ctx, "rebasing onto "+upstream)
+	if output, err := m.runGit(ctx, repo, "rebase", upstream); err != nil {
+		m.runGit(ctx, repo, "rebase", "--abort")
+		return conflictError("rebase", err, output)
+	}
+
+	if report.Local == 0 {
+		report.Action = fmt.Sprintf("fast-forwarded %d commit(s)", report.Incoming)
+	} else {
+		report.Action = fmt.Sprintf("rebased %d local commit(s) onto %d new commit(s)", report.Local, report.Incoming)
+	}
+	return nil
+}
+
+// merge merges upstream into the branch, aborting on conflicts
+func (m *Manager) merge(ctx context.Context, repo *types.Repository, upstream string, report *types.UpdateReport) error {
+	types.ReportProgress(ctx, "merging "+upstream)
+	if output, err := m.runGit(ctx, repo, "merge", "--no-edit", upstream); err != nil {
+		m.runGit(ctx, repo, "merge", "--abort")
+		return conflictError("merge", err, output)
+	}
+
+	if report.Local == 0 {
+		report.Action = fmt.Sprintf("fast-forwarded %d commit(s)", report.Incoming)
+	} else {
+		report.Action = fmt.Sprintf("merged %d new commit(s) with %d local commit(s)", report.Incoming, report.Local)
+	}
+	return nil
+}
+
+// reset hard-resets the branch to upstream, refusing to discard local
+// commits unless forced
+func (m *Manager) reset(ctx context.Context, repo *types.Repository, upstream string, report *types.UpdateReport) error {
+	if report.Local > 0 && !m.updateOptions.Force {
+		if report.Incoming > 0 {
+			return types.NewOperationError(types.ErrorDiverged,
+				fmt.Errorf("branch has diverged from %s (%d local, %d remote commits); refusing to update (use --force to reset)", upstream, report.Local, report.Incoming))
+		}
+		return types.NewOperationError(types.ErrorAhead,
+			fmt.Errorf("branch is %d commit(s) ahead of %s; refusing to discard unpushed commits (use --force to reset)", report.Local, upstream))
+	}
+
+	types.ReportProgress(ctx, "resetting to "+upstream)
+	if output, err := m.runGit(ctx, repo, "reset", "--hard", upstream); err != nil {
+		return newGitError("reset", err, output)
+	}
+
+	report.Action = fmt.Sprintf("reset to %s (%d new commit(s))", upstream, report.Incoming)
+	if report.Local > 0 {
+		report.Action += fmt.Sprintf(", discarded %d local commit(s)", report.Local)
+	}
+	return nil
+}
+
+// conflictError reports a rebase or merge that stopped on conflicts
+func conflictError(command string, err error, output []byte) error {
+	gitErr := newGitError(command, err, output)
+	if gitErr.Category == types.ErrorUnknown && strings.Contains(strings.ToLower(string(output)), "conflict") {
+		gitErr.Category = types.ErrorConflict
+	}
+	return gitErr
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// RemoteURL is a git remote URL split into its parts
+type RemoteURL struct {
+	Scheme string // https, http, ssh, git or file; scp-style URLs report ssh
+	User   string
+	Host   string // Lowercase hostname without port, empty for local paths
+	Port   string
+	Path   string // Repository path without leading slash or .git suffix
+}
+
+// ParseRemoteURL parses HTTPS, ssh:// and scp-style (user@host:path) git URLs.
+// Local paths parse with an empty host.
+func ParseRemoteURL(raw string) (RemoteURL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return RemoteURL{}, fmt.Errorf("empty remote URL")
+	}
+
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return RemoteURL{}, fmt.Errorf("invalid remote URL %q: %w", raw, err)
+		}
+		remote := RemoteURL{
+			Scheme: strings.ToLower(u.Scheme),
+			Host:   strings.ToLower(u.Hostname()),
+			Port:   u.Port(),
+			Path:   cleanRemotePath(u.Path),
+		}
+		if u.User != nil {
+			remote.User = u.User.Username()
+		}
+		return remote, nil
+	}
+
+	// scp-style: [user@]host:path, where host contains no slash. A single
+	// letter before the colon is a Windows drive, not a host.
+	if colon := strings.Index(raw, ":"); colon > 0 && !strings.Contains(raw[:colon], "/") && !strings.Contains(raw[:colon], `\`) {
+		hostPart := raw[:colon]
+		remote := RemoteURL{Scheme: "ssh", Path: cleanRemotePath(raw[colon+1:])}
+		if at := strings.LastIndex(hostPart, "@"); at >= 0 {
+			remote.User = hostPart[:at]
+			hostPart = hostPart[at+1:]
+		}
+		if len(hostPart) > 1 {
+			remote.Host = strings.ToLower(hostPart)
+			return remote, nil
+		}
+	}
+
+	return RemoteURL{Scheme: "file", Path: raw}, nil
+}
+
+// HostFromURL returns the lowercase host of a git remote URL, or an empty
+// string for local paths and unparseable URLs
+func HostFromURL(raw string) string {
+	remote, err := ParseRemoteURL(raw)
+	if err != nil {
+		return ""
+	}
+	return remote.Host
+}
+
+// SameRemote reports whether two remote URLs name the same repository.
+// Hosted URLs match on host and path, so the SSH and HTTPS forms of a
+// repository are equal whatever their user or port. Local paths match after
+// cleaning.
+func SameRemote(a, b string) bool {
+	ra, errA := ParseRemoteURL(a)
+	rb, errB := ParseRemoteURL(b)
+	if errA != nil || errB != nil {
+		return strings.TrimSpace(a) == strings.TrimSpace(b)
+	}
+	if ra.Host != "" || rb.Host != "" {
+		return ra.Host == rb.Host && ra.Path == rb.Path
+	}
+	return localRemotePath(ra) == localRemotePath(rb)
+}
+
+// localRemotePath normalizes a local remote, given as a path or file:// URL,
+// for comparison
+func localRemotePath(remote RemoteURL) string {
+	path := filepath.ToSlash(filepath.Clean(remote.Path))
+	return strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+}
+
+// cleanRemotePath strips the leading slash and .git suffix from a remote path
+func cleanRemotePath(path string) string {
+	path = strings.Trim(path, "/")
+	return strings.TrimSuffix(path, ".git")
+}
@@ -0,0 +1,71 @@
+package repository
+
+import "testing"
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		raw    string
+		scheme string
+		user   string
+		host   string
+		port   string
+		path   string
+	}{
+		{"https://github.com/LederWorks/gorepos.git", "https", "", "github.com", "", "LederWorks/gorepos"},
+		{"https://user@GitHub.com:8443/org/repo", "https", "user", "github.com", "8443", "org/repo"},
+		{"ssh://git@gitlab.example.com:2222/group/sub/repo.git", "ssh", "git", "gitlab.example.com", "2222", "group/sub/repo"},
+		{"git@github.com:LederWorks/gorepos.git", "ssh", "git", "github.com", "", "LederWorks/gorepos"},
+		{"github.com:org/repo", "ssh", "", "github.com", "", "org/repo"},
+		{"/srv/git/repo.git", "file", "", "", "", "/srv/git/repo.git"},
+		{`C:\git\repo`, "file", "", "", "", `C:\git\repo`},
+		{"../relative/repo", "file", "", "", "", "../relative/repo"},
+	}
+
+	for _, tt := range tests {
+		remote, err := ParseRemoteURL(tt.raw)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.raw, err)
+			continue
+		}
+		if remote.Scheme != tt.scheme || remote.User != tt.user || remote.Host != tt.host || remote.Port != tt.port || remote.Path != tt.path {
+			t.Errorf("%s: got %+v", tt.raw, remote)
+		}
+	}
+}
+
+func TestParseRemoteURL_Empty(t *testing.T) {
+	if _, err := ParseRemoteURL("  "); err == nil {
+		t.Error("expected error for empty URL")
+	}
+}
+
+func TestHostFromURL(t *testing.T) {
+	if host := HostFromURL("git@dev.azure.com:v3/org/project/repo"); host != "dev.azure.com" {
+		t.Errorf("expected dev.azure.com, got %q", host)
+	}
+	if host := HostFromURL("/local/path"); host != "" {
+		t.Errorf("expected empty host for local path, got %q", host)
+	}
+}
+
+func TestSameRemote(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://github.com/org/repo.git", "git@github.com:org/repo.git", true},
+		{"https://github.com/org/repo", "ssh://git@GitHub.com:22/org/repo.git", true},
+		{"https://user@github.com/org/repo.git", "https://github.com/org/repo", true},
+		{"https://github.com/old-org/repo.git", "git@github.com:new-org/repo.git", false},
+		{"https://github.com/org/repo.git", "https://gitlab.com/org/repo.git", false},
+		{"/srv/git/repo.git", "file:///srv/git/repo", true},
+		{"/srv/git/repo.git", "/srv/git/other.git", false},
+		{"/srv/git/repo.git", "https://github.com/srv/git/repo.git", false},
+	}
+
+	for _, tt := range tests {
+		if got := SameRemote(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameRemote(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
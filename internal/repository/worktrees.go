@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+// WorktreePath returns the absolute path of a configured worktree, resolved
+// against the base path like repository paths
+func (m *Manager) WorktreePath(wt types.Worktree) string {
+	if filepath.IsAbs(wt.Path) || m.basePath == "" {
+		return wt.Path
+	}
+	return filepath.Join(m.basePath, wt.Path)
+}
+
+// MissingWorktrees returns the repository's configured worktrees that are not
+// checked out
+func (m *Manager) MissingWorktrees(repo *types.Repository) []types.Worktree {
+	var missing []types.Worktree
+	for _, wt := range repo.Worktrees {
+		if !isGitCheckout(m.WorktreePath(wt)) {
+			missing = append(missing, wt)
+		}
+	}
+	return missing
+}
+
+// EnsureWorktrees creates the repository's configured worktrees that do not
+// exist yet and returns their paths. A branch that exists only on origin is
+// checked out as a new local tracking branch.
+func (m *Manager) EnsureWorktrees(ctx context.Context, repo *types.Repository) ([]string, error) {
+	var created []string
+	for _, wt := range m.MissingWorktrees(repo) {
+		path := m.WorktreePath(wt)
+		types.ReportProgress(ctx, "adding worktree "+wt.Path)
+		if output, err := m.runGit(ctx, repo, "worktree", "add", path, wt.Branch); err != nil {
+			return created, newGitError("worktree add", err, output)
+		}
+		created = append(created, path)
+	}
+	return created, nil
+}
+
+// Worktrees lists the repository's configured worktrees together with any
+// other worktrees git has registered for it. The main working tree is not
+// included. Repositories without configured or registered worktrees return
+// nothing without running git.
+func (m *Manager) Worktrees(ctx context.Context, repo *types.Repository) ([]types.WorktreeStatus, error) {
+	if len(repo.Worktrees) == 0 {
+		if _, err := os.Stat(filepath.Join(m.getRepoPath(repo), ".git", "worktrees")); err != nil {
+			return nil, nil
+		}
+	}
+
+	output, err := m.runGit(ctx, repo, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, newGitError("worktree list", err, output)
+	}
+
+	registered := parseWorktreeList(string(output))
+	if len(registered) > 0 {
+		registered = registered[1:] // The first entry is the main working tree
+	}
+
+	var worktrees []types.WorktreeStatus
+	seen := make(map[int]bool)
+	for _, wt := range repo.Worktrees {
+		status := types.WorktreeStatus{Path: m.WorktreePath(wt), Branch: wt.Branch, Configured: true}
+		for i, entry := range registered {
+			if samePath(entry.Path, status.Path) {
+				seen[i] = true
+				status.CurrentBranch = entry.CurrentBranch
+				status.Exists = isGitCheckout(status.Path)
+			}
+		}
+		worktrees = append(worktrees, status)
+	}
+	for i, entry := range registered {
+		if !seen[i] {
+			entry.Branch = entry.CurrentBranch
+			entry.Exists = isGitCheckout(entry.Path)
+			worktrees = append(worktrees, entry)
+		}
+	}
+
+	for i := range worktrees {
+		if worktrees[i].Exists {
+			worktrees[i].IsClean = m.worktreeClean(ctx, repo, worktrees[i].Path)
+		}
+	}
+	return worktrees, nil
+}
+
+// RemoveWorktree removes a worktree and prunes git's record of it. Worktrees
+// with uncommitted changes are refused by git and left in place.
+func (m *Manager) RemoveWorktree(ctx context.Context, repo *types.Repository, path string) error {
+	if isGitCheckout(path) {
+		if output, err := m.runGit(ctx, repo, "worktree", "remove", path); err != nil {
+			return newGitError("worktree remove", err, output)
+		}
+	}
+	if output, err := m.runGit(ctx, repo, "worktree", "prune"); err != nil {
+		return newGitError("worktree prune", err, output)
+	}
+	return nil
+}
+
+// worktreeStatus lists worktrees for Status, which reports what it can
+func (m *Manager) worktreeStatus(ctx context.Context, repo *types.Repository) []types.WorktreeStatus {
+	worktrees, err := m.Worktrees(ctx, repo)
+	if err != nil {
+		return nil
+	}
+	return worktrees
+}
+
+// worktreeClean reports whether a worktree has no uncommitted changes
+func (m *Manager) worktreeClean(ctx context.Context, repo *types.Repository, path string) bool {
+	output, err := m.runGit(ctx, repo, "-C", path, "status", "--porcelain")
+	return err == nil && strings.TrimSpace(string(output)) == ""
+}
+
+// parseWorktreeList parses git worktree list --porcelain output into one
+// entry per worktree, in git's order
+func parseWorktreeList(output string) []types.WorktreeStatus {
+	var worktrees []types.WorktreeStatus
+	for _, block := range strings.Split(strings.TrimSpace(output), "\n\n") {
+		var wt types.WorktreeStatus
+		for _, line := range strings.Split(block, "\n") {
+			key, value, _ := strings.Cut(line, " ")
+			switch key {
+			case "worktree":
+				wt.Path = value
+			case "branch":
+				wt.CurrentBranch = strings.TrimPrefix(value, "refs/heads/")
+			}
+		}
+		if wt.Path != "" {
+			worktrees = append(worktrees, wt)
+		}
+	}
+	return worktrees
+}
+
+// samePath reports whether two paths name the same directory. Git records
+// worktree paths with symlinks resolved, so both sides are resolved too.
+func samePath(a, b string) bool {
+	return resolvePath(a) == resolvePath(b)
+}
+
+// resolvePath cleans a path and resolves symlinks, falling back to its parent
+// directory when the path itself no longer exists
+func resolvePath(path string) string {
+	path = filepath.Clean(path)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	if parent, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+		return filepath.Join(parent, filepath.Base(path))
+	}
+	return path
+}
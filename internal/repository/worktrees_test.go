@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LederWorks/gorepos/pkg/types"
+)
+
+func TestParseWorktreeList(t *testing.T) {
+	output := "worktree /src/app\nHEAD 1111\nbranch refs/heads/main\n\n" +
+		"worktree /src/app-release\nHEAD 2222\nbranch refs/heads/release\n\n" +
+		"worktree /src/app-detached\nHEAD 3333\ndetached\n"
+
+	got := parseWorktreeList(output)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 worktrees, got %+v", got)
+	}
+	if got[1].Path != "/src/app-release" || got[1].CurrentBranch != "release" {
+		t.Errorf("unexpected release worktree %+v", got[1])
+	}
+	if got[2].CurrentBranch != "" {
+		t.Errorf("expected detached worktree without branch, got %q", got[2].CurrentBranch)
+	}
+}
+
+func TestExists_AcceptsWorktree(t *testing.T) {
+	src := initLocalRepo(t)
+	wt := filepath.Join(t.TempDir(), "wt")
+	run(t, src, "git", "worktree", "add", "-b", "feature", wt)
+
+	m := NewManager("")
+	if !m.Exists(&types.Repository{Path: wt}) {
+		t.Error("expected a worktree with a .git file to exist")
+	}
+
+	plain := t.TempDir()
+	if err := os.WriteFile(filepath.Join(plain, ".git"), []byte("not a pointer"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if m.Exists(&types.Repository{Path: plain}) {
+		t.Error("expected a .git file without gitdir to be rejected")
+	}
+}
+
+func TestCloneCreatesWorktrees(t *testing.T) {
+	src := initLocalRepo(t)
+	run(t, src, "git", "branch", "release")
+	basePath := t.TempDir()
+
+	m := NewManager(basePath)
+	repo := &types.Repository{
+		Name:      "app",
+		Path:      "app",
+		URL:       src,
+		Worktrees: []types.Worktree{{Branch: "release", Path: "app-release"}},
+	}
+	if err := m.Clone(context.Background(), repo); err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+
+	worktrees, err := m.Worktrees(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("worktrees: %v", err)
+	}
+	if len(worktrees) != 1 {
+		t.Fatalf("expected 1 worktree, got %+v", worktrees)
+	}
+	wt := worktrees[0]
+	if !wt.Exists || !wt.Configured || !wt.IsClean || wt.CurrentBranch != "release" {
+		t.Errorf("expected clean configured worktree on release, got %+v", wt)
+	}
+	if len(m.MissingWorktrees(repo)) != 0 {
+		t.Error("expected no missing worktrees after clone")
+	}
+}
+
+func TestWorktrees_ReportsAndRemovesUnconfigured(t *testing.T) {
+	src := initLocalRepo(t)
+	dest := cloneLocalRepo(t, src)
+	stale := filepath.Join(t.TempDir(), "stale")
+	run(t, dest, "git", "worktree", "add", "-b", "old-feature", stale)
+
+	m := NewManager("")
+	repo := &types.Repository{Name: "test", Path: dest, URL: src, Branch: "master"}
+
+	status, err := m.Status(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if len(status.Worktrees) != 1 || status.Worktrees[0].Configured || status.Worktrees[0].Branch != "old-feature" {
+		t.Fatalf("expected one unconfigured worktree, got %+v", status.Worktrees)
+	}
+
+	if err := m.RemoveWorktree(context.Background(), repo, status.Worktrees[0].Path); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("expected worktree directory to be removed, got %v", err)
+	}
+	worktrees, err := m.Worktrees(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("worktrees: %v", err)
+	}
+	if len(worktrees) != 0 {
+		t.Errorf("expected no worktrees after removal, got %+v", worktrees)
+	}
+}
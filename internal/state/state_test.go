@@ -0,0 +1,44 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	got := Path("/work", "journal.json")
+	if got != filepath.Join("/work", DirName, "journal.json") {
+		t.Errorf("unexpected state path %q", got)
+	}
+}
+
+func TestWriteReadJSON(t *testing.T) {
+	path := Path(t.TempDir(), "test.json")
+	want := map[string]int{"a": 1}
+
+	if err := WriteJSON(path, want); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	var got map[string]int
+	if err := ReadJSON(path, &got); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if got["a"] != 1 {
+		t.Errorf("unexpected round trip: %v", got)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected only the state file, found %d entries", len(entries))
+	}
+}
+
+func TestReadJSON_Missing(t *testing.T) {
+	var v map[string]int
+	if err := ReadJSON(filepath.Join(t.TempDir(), "missing.json"), &v); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+}
@@ -46,11 +46,15 @@ func (b *GraphBuilder) BuildGraph(rootPath string) (GraphQuery, error) {
 		return nil, fmt.Errorf("failed to build configuration hierarchy: %w", err)
 	}
 
-	// Process repositories and groups
+	// Process repositories, their dependencies and groups
 	if err := b.processRepositories(graph); err != nil {
 		return nil, fmt.Errorf("failed to process repositories: %w", err)
 	}
 
+	if err := b.processDependencies(graph); err != nil {
+		return nil, fmt.Errorf("failed to process dependencies: %w", err)
+	}
+
 	if err := b.processGroups(graph); err != nil {
 		return nil, fmt.Errorf("failed to process groups: %w", err)
 	}
@@ -254,6 +258,34 @@ func (b *GraphBuilder) processRepositories(graph *RepositoryGraphImpl) error {
 	return nil
 }
 
+// processDependencies creates depends_on relationships from each repository's dependsOn list
+func (b *GraphBuilder) processDependencies(graph *RepositoryGraphImpl) error {
+	for _, repoNode := range graph.GetNodesByType(NodeTypeRepository) {
+		if repoNode.Repository == nil {
+			continue
+		}
+
+		for _, depName := range repoNode.Repository.DependsOn {
+			depNode, exists := graph.AllRepositories[depName]
+			if !exists {
+				return fmt.Errorf("repository %s depends on unknown repository %s", repoNode.Name, depName)
+			}
+
+			dependsRel := NewRelationship(
+				fmt.Sprintf("dep_%s_%s", repoNode.ID, depNode.ID),
+				repoNode,
+				depNode,
+				RelationDependsOn,
+			)
+			if err := graph.AddRelationship(dependsRel); err != nil {
+				return fmt.Errorf("failed to add depends_on relationship: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // createRepositoryNode creates a repository node
 func (b *GraphBuilder) createRepositoryNode(repo *types.Repository, configNode *GraphNode) *GraphNode {
 	// Generate unique ID
@@ -280,6 +312,9 @@ func (b *GraphBuilder) createRepositoryNode(repo *types.Repository, configNode *
 	repoNode.SetProperty("path", repo.Path)
 	repoNode.SetProperty("branch", repo.Branch)
 	repoNode.SetProperty("disabled", repo.Disabled)
+	if len(repo.DependsOn) > 0 {
+		repoNode.SetProperty("dependsOn", repo.DependsOn)
+	}
 
 	// Store repository tags and labels for later processing
 	if repo.Tags != nil {
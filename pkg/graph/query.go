@@ -460,6 +460,16 @@ func (g *RepositoryGraphImpl) ValidateGraph() error {
 		}
 	}
 
+	// Check for cycles in repository dependencies
+	visited = make(map[string]bool)
+	for _, node := range g.NodesByType[NodeTypeRepository] {
+		if !visited[node.ID] {
+			if err := g.checkDependencyCycles(node, visited, make(map[string]bool)); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -548,6 +558,32 @@ func (g *RepositoryGraphImpl) checkCycles(node *GraphNode, visited, path map[str
 	return nil
 }
 
+// checkDependencyCycles walks dependsOn relations from node and reports the first cycle it finds
+func (g *RepositoryGraphImpl) checkDependencyCycles(node *GraphNode, visited, path map[string]bool) error {
+	if path[node.ID] {
+		return fmt.Errorf("dependency cycle detected involving repository %s", node.Name)
+	}
+
+	if visited[node.ID] {
+		return nil
+	}
+
+	visited[node.ID] = true
+	path[node.ID] = true
+
+	for _, rel := range g.RelationsByFrom[node.ID] {
+		if rel.Type != RelationDependsOn {
+			continue
+		}
+		if err := g.checkDependencyCycles(rel.To, visited, path); err != nil {
+			return err
+		}
+	}
+
+	path[node.ID] = false
+	return nil
+}
+
 // PrintDebugInfo prints debug information about the graph
 func (g *RepositoryGraphImpl) PrintDebugInfo() {
 	fmt.Println("=== Repository Graph Debug Info ===")
@@ -674,3 +674,29 @@ func TestValidateGraph_WithCycle(t *testing.T) {
 		t.Error("expected cycle detection error")
 	}
 }
+
+func TestValidateGraph_DependencyCycle(t *testing.T) {
+	g := newTestGraph()
+	a := addNode(t, g, "repo_a", NodeTypeRepository, "a")
+	b := addNode(t, g, "repo_b", NodeTypeRepository, "b")
+	addRel(t, g, "dep_a_b", a, b, RelationDependsOn)
+	addRel(t, g, "dep_b_a", b, a, RelationDependsOn)
+
+	if err := g.ValidateGraph(); err == nil {
+		t.Error("expected dependency cycle detection error")
+	}
+}
+
+func TestValidateGraph_DependencyChainIsValid(t *testing.T) {
+	g := newTestGraph()
+	a := addNode(t, g, "repo_a", NodeTypeRepository, "a")
+	b := addNode(t, g, "repo_b", NodeTypeRepository, "b")
+	c := addNode(t, g, "repo_c", NodeTypeRepository, "c")
+	addRel(t, g, "dep_a_b", a, b, RelationDependsOn)
+	addRel(t, g, "dep_b_c", b, c, RelationDependsOn)
+	addRel(t, g, "dep_a_c", a, c, RelationDependsOn)
+
+	if err := g.ValidateGraph(); err != nil {
+		t.Errorf("expected valid dependency graph, got: %v", err)
+	}
+}
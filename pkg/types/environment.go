@@ -0,0 +1,36 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// ExpandEnvTemplate expands an environment value as a template over the
+// repository. Values that fail to expand are used as written; configuration
+// validation reports them with RenderEnvTemplate.
+func ExpandEnvTemplate(value string, repo *Repository) string {
+	expanded, err := RenderEnvTemplate(value, repo)
+	if err != nil {
+		return value
+	}
+	return expanded
+}
+
+// RenderEnvTemplate expands an environment value as a template over the
+// repository, failing on syntax errors and unknown fields
+func RenderEnvTemplate(value string, repo *Repository) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+
+	tmpl, err := template.New("env").Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %w", value, err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, repo); err != nil {
+		return "", fmt.Errorf("cannot expand %q: %w", value, err)
+	}
+	return out.String(), nil
+}
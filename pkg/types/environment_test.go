@@ -0,0 +1,20 @@
+package types
+
+import "testing"
+
+func TestRenderEnvTemplate(t *testing.T) {
+	repo := &Repository{Name: "app", Path: "team/app"}
+
+	if got, err := RenderEnvTemplate("{{ .Name }}-{{ .Path }}", repo); err != nil || got != "app-team/app" {
+		t.Errorf("got %q, %v", got, err)
+	}
+	if _, err := RenderEnvTemplate("{{ .Missing }}", repo); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+	if _, err := RenderEnvTemplate("{{ .Name", repo); err == nil {
+		t.Error("expected an error for invalid syntax")
+	}
+	if got := ExpandEnvTemplate("{{ .Name", repo); got != "{{ .Name" {
+		t.Errorf("expected invalid templates to be used as written, got %q", got)
+	}
+}
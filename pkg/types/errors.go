@@ -0,0 +1,75 @@
+package types
+
+import "errors"
+
+// ErrorCategory classifies why an operation failed
+type ErrorCategory string
+
+const (
+	ErrorNone     ErrorCategory = ""
+	ErrorNetwork  ErrorCategory = "network"
+	ErrorAuth     ErrorCategory = "auth"
+	ErrorNotFound ErrorCategory = "not_found"
+	ErrorDirty    ErrorCategory = "dirty"
+	ErrorConflict ErrorCategory = "conflict"
+	ErrorTimeout  ErrorCategory = "timeout"
+	ErrorUnknown  ErrorCategory = "unknown"
+
+	// Refusals: the operation would have discarded local work
+	ErrorAhead       ErrorCategory = "ahead"        // Local commits not on the remote
+	ErrorDiverged    ErrorCategory = "diverged"     // Local and remote both have new commits
+	ErrorWrongBranch ErrorCategory = "wrong_branch" // HEAD is not on the configured branch
+)
+
+// IsRefusal reports whether the category means an operation declined to run
+// to protect local changes, rather than failing
+func (c ErrorCategory) IsRefusal() bool {
+	switch c {
+	case ErrorDirty, ErrorAhead, ErrorDiverged, ErrorWrongBranch:
+		return true
+	}
+	return false
+}
+
+// CategorizedError is implemented by errors that know their failure category
+type CategorizedError interface {
+	error
+	ErrorCategory() ErrorCategory
+}
+
+// OperationError attaches a category to an arbitrary error
+type OperationError struct {
+	Category ErrorCategory
+	Err      error
+}
+
+// NewOperationError wraps err with the given category
+func NewOperationError(category ErrorCategory, err error) *OperationError {
+	return &OperationError{Category: category, Err: err}
+}
+
+func (e *OperationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCategory returns the category of the error
+func (e *OperationError) ErrorCategory() ErrorCategory {
+	return e.Category
+}
+
+// CategoryOf returns the category of the first categorized error in err's chain
+func CategoryOf(err error) ErrorCategory {
+	if err == nil {
+		return ErrorNone
+	}
+
+	var categorized CategorizedError
+	if errors.As(err, &categorized) {
+		return categorized.ErrorCategory()
+	}
+	return ErrorUnknown
+}
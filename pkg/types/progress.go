@@ -0,0 +1,20 @@
+package types
+
+import "context"
+
+// ProgressFunc receives human-readable progress updates from a running operation
+type ProgressFunc func(message string)
+
+type progressKey struct{}
+
+// WithProgress returns a context that routes ReportProgress calls to fn
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ReportProgress sends a progress message to the context's ProgressFunc, if any
+func ReportProgress(ctx context.Context, message string) {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok && fn != nil {
+		fn(message)
+	}
+}
@@ -2,20 +2,60 @@ package types
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
 // Repository represents a single repository configuration
 type Repository struct {
-	Name        string                 `yaml:"name" validate:"required,min=1"`
-	Path        string                 `yaml:"path" validate:"required,min=1"`
-	URL         string                 `yaml:"url" validate:"required,url"`
-	Branch      string                 `yaml:"branch,omitempty"`
-	Commands    map[string]string      `yaml:"commands,omitempty"`
-	Environment map[string]string      `yaml:"environment,omitempty"`
-	Tags        map[string]interface{} `yaml:"tags,omitempty"`   // Key-value pairs
-	Labels      []string               `yaml:"labels,omitempty"` // Simple labels
-	Disabled    bool                   `yaml:"disabled,omitempty"`
+	Name           string                 `yaml:"name" validate:"required,min=1"`
+	Path           string                 `yaml:"path" validate:"required,min=1"`
+	URL            string                 `yaml:"url" validate:"required,url"`
+	Branch         string                 `yaml:"branch,omitempty"`
+	Commands       map[string]string      `yaml:"commands,omitempty"`
+	Environment    map[string]string      `yaml:"environment,omitempty"`
+	Tags           map[string]interface{} `yaml:"tags,omitempty"`   // Key-value pairs
+	Labels         []string               `yaml:"labels,omitempty"` // Simple labels
+	Disabled       bool                   `yaml:"disabled,omitempty"`
+	Timeout        time.Duration          `yaml:"timeout,omitempty" validate:"omitempty,min=1s"` // Overrides global timeout
+	DependsOn      []string               `yaml:"dependsOn,omitempty"`                           // Names of repositories whose clone, update or fork sync must succeed first
+	Priority       int                    `yaml:"priority,omitempty"`                            // Higher runs first; raised by group and tag priorities
+	UpdateStrategy UpdateStrategy         `yaml:"updateStrategy,omitempty"`                      // Inherits the global strategy, defaults to ff-only
+	Autostash      *bool                  `yaml:"autostash,omitempty"`                           // Stash uncommitted changes around updates; unset inherits the global setting
+	Clone          *CloneOptions          `yaml:"clone,omitempty"`                               // Unset fields inherit the global clone options
+	Submodules     SubmoduleMode          `yaml:"submodules,omitempty"`                          // recursive initializes and updates submodules; defaults to none
+	LFS            bool                   `yaml:"lfs,omitempty"`                                 // Fetch and check out Git LFS objects
+	Worktrees      []Worktree             `yaml:"worktrees,omitempty" validate:"dive"`           // Extra working trees created from the main clone
+	Remotes        map[string]string      `yaml:"remotes,omitempty"`                             // Extra remotes by name; origin is always URL
+	Mode           CloneMode              `yaml:"mode,omitempty"`                                // Inherits the global mode, defaults to checkout
+	Credentials    *CredentialConfig      `yaml:"credentials,omitempty"`                         // Overrides the global and host credentials field by field
+
+	// ScopeEnvironment holds global.environment of the file declaring the
+	// repository and of the files including it; the nearest file wins. Set by the loader.
+	ScopeEnvironment map[string]string `yaml:"-"`
+}
+
+// AutostashEnabled reports whether updates stash uncommitted changes, which
+// is off unless set
+func (r *Repository) AutostashEnabled() bool {
+	return r.Autostash != nil && *r.Autostash
+}
+
+// CloneMode selects what kind of clone a repository is kept as
+type CloneMode string
+
+const (
+	ModeCheckout CloneMode = "checkout" // A working tree on the tracked branch
+	ModeMirror   CloneMode = "mirror"   // A bare copy of every ref, for backups
+)
+
+// UpstreamRemote is the remote a fork is synchronized from by sync-forks
+const UpstreamRemote = "upstream"
+
+// Worktree is an additional working tree of a repository with its own branch checked out
+type Worktree struct {
+	Branch string `yaml:"branch" validate:"required"`
+	Path   string `yaml:"path" validate:"required"` // Relative to basePath unless absolute
 }
 
 // Config represents the complete configuration structure
@@ -30,20 +70,99 @@ type Config struct {
 
 // GlobalConfig contains global settings
 type GlobalConfig struct {
-	BasePath    string                 `yaml:"basePath,omitempty"`
-	Workers     int                    `yaml:"workers,omitempty" validate:"omitempty,min=1,max=100"`
-	Timeout     time.Duration          `yaml:"timeout,omitempty" validate:"omitempty,min=1s"`
-	Environment map[string]string      `yaml:"environment,omitempty"`
-	Tags        map[string]interface{} `yaml:"tags,omitempty"`   // Global key-value tags
-	Labels      []string               `yaml:"labels,omitempty"` // Global simple labels
-	Credentials *CredentialConfig      `yaml:"credentials,omitempty"`
+	BasePath       string                 `yaml:"basePath,omitempty"`
+	Workers        int                    `yaml:"workers,omitempty" validate:"omitempty,min=1,max=100"`
+	Timeout        time.Duration          `yaml:"timeout,omitempty" validate:"omitempty,min=1s"`
+	Environment    map[string]string      `yaml:"environment,omitempty"`
+	Tags           map[string]interface{} `yaml:"tags,omitempty"`   // Global key-value tags
+	Labels         []string               `yaml:"labels,omitempty"` // Global simple labels
+	Credentials    *CredentialConfig      `yaml:"credentials,omitempty"`
+	Retry          *RetryConfig           `yaml:"retry,omitempty"`
+	HostLimits     *HostLimitConfig       `yaml:"hostLimits,omitempty"`
+	Priorities     *PriorityConfig        `yaml:"priorities,omitempty"`
+	UpdateStrategy UpdateStrategy         `yaml:"updateStrategy,omitempty"`
+	Autostash      *bool                  `yaml:"autostash,omitempty"`
+	Clone          *CloneOptions          `yaml:"clone,omitempty"`
+	Mode           CloneMode              `yaml:"mode,omitempty"`
 }
 
 // CredentialConfig handles credential management
 type CredentialConfig struct {
-	SSHKeyPath    string `yaml:"sshKeyPath,omitempty"`
-	GitCredHelper string `yaml:"gitCredHelper,omitempty"`
-	TokenEnvVar   string `yaml:"tokenEnvVar,omitempty"`
+	SSHKeyPath    string                      `yaml:"sshKeyPath,omitempty"`    // Private key passed to ssh through GIT_SSH_COMMAND
+	GitCredHelper string                      `yaml:"gitCredHelper,omitempty"` // Replaces any configured git credential helpers
+	TokenEnvVar   string                      `yaml:"tokenEnvVar,omitempty"`   // Environment variable holding an HTTPS access token
+	TokenUser     string                      `yaml:"tokenUser,omitempty"`     // Username sent with the token, defaults to x-access-token
+	Hosts         map[string]CredentialConfig `yaml:"hosts,omitempty"`         // Overrides by host pattern, e.g. "*.example.com"; global only
+}
+
+// RetryConfig controls retries of transient operation failures
+type RetryConfig struct {
+	MaxAttempts    int             `yaml:"maxAttempts,omitempty" validate:"omitempty,min=1,max=10"`
+	InitialBackoff time.Duration   `yaml:"initialBackoff,omitempty"`
+	MaxBackoff     time.Duration   `yaml:"maxBackoff,omitempty"`
+	Categories     []ErrorCategory `yaml:"categories,omitempty"` // Retryable categories, defaults to network and timeout
+}
+
+// CloneOptions reduce what a clone downloads and checks out
+type CloneOptions struct {
+	Depth        int      `yaml:"depth,omitempty" validate:"omitempty,min=1"` // Shallow clone with this many commits
+	Filter       string   `yaml:"filter,omitempty"`                           // Partial clone filter, e.g. blob:none
+	SingleBranch *bool    `yaml:"singleBranch,omitempty"`                     // Fetch only the tracked branch; unset inherits the global option
+	SparsePaths  []string `yaml:"sparsePaths,omitempty"`                      // Directories to check out in sparse-checkout cone mode
+}
+
+// SingleBranchEnabled reports whether only the tracked branch is fetched,
+// which is off unless set
+func (o *CloneOptions) SingleBranchEnabled() bool {
+	return o.SingleBranch != nil && *o.SingleBranch
+}
+
+// SubmoduleMode selects how clone and update handle submodules
+type SubmoduleMode string
+
+const (
+	SubmodulesNone      SubmoduleMode = "none"      // Leave submodules alone
+	SubmodulesRecursive SubmoduleMode = "recursive" // Initialize and update submodules recursively
+)
+
+// HostLimitConfig caps concurrent network operations against a single git host
+type HostLimitConfig struct {
+	Default int            `yaml:"default,omitempty"` // Cap for hosts without an entry, 0 means unlimited
+	Hosts   map[string]int `yaml:"hosts,omitempty"`   // Per-host caps keyed by hostname
+}
+
+// PriorityConfig assigns scheduling priorities to groups and tags. A tag key
+// matches any value; "key=value" matches that value only.
+type PriorityConfig struct {
+	Groups map[string]int `yaml:"groups,omitempty"`
+	Tags   map[string]int `yaml:"tags,omitempty"`
+}
+
+// UpdateStrategy selects how update integrates remote changes into the local branch
+type UpdateStrategy string
+
+const (
+	StrategyFFOnly UpdateStrategy = "ff-only" // Fast-forward only, refuse diverged branches
+	StrategyRebase UpdateStrategy = "rebase"  // Rebase local commits onto the remote branch
+	StrategyMerge  UpdateStrategy = "merge"   // Merge the remote branch into the local branch
+	StrategyReset  UpdateStrategy = "reset"   // Hard reset to the remote branch
+)
+
+// UpdateReport describes what an update did
+type UpdateReport struct {
+	Strategy UpdateStrategy // Empty for mirror clones, which have no branch to integrate
+	Action   string         // Human-readable outcome, e.g. "fast-forwarded 3 commit(s)"
+	Incoming int            // Remote commits not yet in the local branch before the update
+	Local    int            // Local commits not yet on the remote branch before the update
+	Stashed  bool           // Uncommitted changes were stashed and re-applied
+}
+
+// String returns the strategy and outcome, e.g. "ff-only: fast-forwarded 3 commit(s)"
+func (r *UpdateReport) String() string {
+	if r.Strategy == "" {
+		return r.Action
+	}
+	return fmt.Sprintf("%s: %s", r.Strategy, r.Action)
 }
 
 // Operation represents a repository operation
@@ -54,21 +173,39 @@ type Operation struct {
 	Context    context.Context
 }
 
+// ResultState describes how an operation finished
+type ResultState string
+
+const (
+	ResultSucceeded ResultState = "succeeded"
+	ResultFailed    ResultState = "failed"
+	ResultTimedOut  ResultState = "timed_out"
+	ResultCancelled ResultState = "cancelled"
+	ResultSkipped   ResultState = "skipped"
+	ResultRefused   ResultState = "refused" // Declined to overwrite local changes
+)
+
 // Result represents the result of a repository operation
 type Result struct {
 	Repository *Repository
 	Operation  string
+	State      ResultState
 	Success    bool
 	Output     string
 	Error      error
+	ExitCode   int           // Exit status of commands run by exec operations, -1 when the command could not run
+	Category   ErrorCategory // Failure classification, empty on success
+	Attempts   int
 	Duration   time.Duration
 	StartTime  time.Time
+	Status     *RepoStatus   // Populated by status operations
+	Update     *UpdateReport // Populated by update operations
 }
 
 // RepositoryManager interface for repository operations
 type RepositoryManager interface {
 	Clone(ctx context.Context, repo *Repository) error
-	Update(ctx context.Context, repo *Repository) error
+	Update(ctx context.Context, repo *Repository) (*UpdateReport, error)
 	Status(ctx context.Context, repo *Repository) (*RepoStatus, error)
 	Execute(ctx context.Context, repo *Repository, command string, args ...string) (*Result, error)
 	Exists(repo *Repository) bool
@@ -78,11 +215,85 @@ type RepositoryManager interface {
 type RepoStatus struct {
 	Path             string
 	CurrentBranch    string
+	Branch           string            // Branch the repository tracks, empty when it could not be resolved
+	BranchSource     BranchSource      // Whether Branch was configured or detected from the remote
+	Shallow          bool              // The clone has truncated history
+	Sparse           bool              // Only part of the tree is checked out
+	Submodules       []SubmoduleStatus // Submodules not checked out at their recorded commit
+	Worktrees        []WorktreeStatus  // Configured worktrees and any others git knows about
+	Remotes          []RemoteStatus    // Origin and configured remotes, set only when extra remotes are configured
+	OriginURL        string            // URL of origin in the clone, empty when it has no origin
+	OriginMismatch   bool              // Origin names a different repository than the configured url
+	Mirror           *MirrorStatus     // Set for mirror clones, which have no working tree
 	IsClean          bool
-	UncommittedFiles []string
+	UncommittedFiles []string // Every changed path: staged, unstaged, untracked and conflicted
+	Staged           []string // Paths with changes in the index
+	Unstaged         []string // Tracked paths with changes in the working tree
+	Untracked        []string
+	Conflicted       []string // Paths with unresolved merge conflicts
+	StashCount       int
+	Detached         bool   // HEAD is not on a branch
+	Upstream         string // Upstream of the checked out branch, e.g. origin/main; empty when unset
+	LastCommit       *CommitInfo
 	AheadBehind      *BranchComparison
 }
 
+// CommitInfo identifies a commit
+type CommitInfo struct {
+	Hash   string
+	Author string
+	Date   time.Time
+}
+
+// BranchSource tells where a repository's tracked branch came from
+type BranchSource string
+
+const (
+	BranchConfigured BranchSource = "configured" // Set with branch: in the configuration
+	BranchDetected   BranchSource = "detected"   // The remote's default branch
+)
+
+// MirrorStatus describes a bare mirror clone
+type MirrorStatus struct {
+	LastFetch time.Time // When refs were last fetched; zero when unknown
+	Branches  int
+	Tags      int
+	Refs      int // All refs, including branches and tags
+}
+
+// RemoteStatus compares the local branch with the tracked branch on one remote
+type RemoteStatus struct {
+	Name        string
+	URL         string
+	AheadBehind *BranchComparison // Nil when the remote has no such branch or has not been fetched
+}
+
+// WorktreeStatus describes a worktree of a repository
+type WorktreeStatus struct {
+	Path          string
+	Branch        string // Configured branch, or the checked out branch for unconfigured worktrees
+	CurrentBranch string // Branch checked out in the worktree, empty when detached or missing
+	Exists        bool   // Present on disk and registered with git
+	Configured    bool   // Declared under worktrees: in the configuration
+	IsClean       bool
+}
+
+// SubmoduleState describes how a submodule differs from its recorded commit
+type SubmoduleState string
+
+const (
+	SubmoduleUninitialized SubmoduleState = "uninitialized" // Not cloned into the working tree
+	SubmoduleOutOfSync     SubmoduleState = "out_of_sync"   // Checked out at a different commit
+	SubmoduleConflict      SubmoduleState = "conflict"      // Has merge conflicts
+)
+
+// SubmoduleStatus reports a submodule that is not at its recorded commit
+type SubmoduleStatus struct {
+	Path   string
+	Commit string // Commit currently checked out, or recorded when uninitialized
+	State  SubmoduleState
+}
+
 // BranchComparison shows commits ahead/behind upstream
 type BranchComparison struct {
 	Ahead  int